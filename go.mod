@@ -8,11 +8,13 @@ require (
 	github.com/hashicorp/terraform-plugin-sdk/v2 v2.13.0
 	github.com/orirawlings/persistent-cookiejar v0.3.2
 	github.com/pkg/errors v0.9.1
+	github.com/pquerna/otp v1.4.0
 )
 
 require (
 	github.com/agext/levenshtein v1.2.2 // indirect
 	github.com/apparentlymart/go-textseg/v13 v13.0.0 // indirect
+	github.com/boombuler/barcode v1.0.1-0.20190219062509-6c824513bacc // indirect
 	github.com/fatih/color v1.7.0 // indirect
 	github.com/frankban/quicktest v1.14.4 // indirect
 	github.com/golang/protobuf v1.5.2 // indirect
@@ -0,0 +1,161 @@
+// Package apitest provides a stub of the INWX api for tests of code using api.Client.
+package apitest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/inwx/terraform-provider-inwx/inwx/internal/api"
+)
+
+// Handler returns the response of an api call for its parameters.
+type Handler func(params map[string]interface{}) map[string]interface{}
+
+// Call is a single api call received by a Stub.
+type Call struct {
+	Method string
+	Params map[string]interface{}
+}
+
+// Stub answers api calls with canned responses by method and records all calls. It is used as transport of an
+// api.Client, calls of methods without handler are answered with an error response.
+type Stub struct {
+	mu       sync.Mutex
+	handlers map[string]Handler
+	calls    []Call
+}
+
+func NewStub() *Stub {
+	return &Stub{
+		handlers: map[string]Handler{},
+	}
+}
+
+// Handle answers calls of method with the response of handler.
+func (s *Stub) Handle(method string, handler Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.handlers[method] = handler
+}
+
+// Respond answers all calls of method with response.
+func (s *Stub) Respond(method string, response map[string]interface{}) {
+	s.Handle(method, func(map[string]interface{}) map[string]interface{} {
+		return response
+	})
+}
+
+// Calls returns the received calls of method in order.
+func (s *Stub) Calls(method string) []Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var calls []Call
+	for _, call := range s.calls {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// LastCall returns the parameters of the last call of method, or nil if it was not called.
+func (s *Stub) LastCall(method string) map[string]interface{} {
+	calls := s.Calls(method)
+	if len(calls) == 0 {
+		return nil
+	}
+	return calls[len(calls)-1].Params
+}
+
+// RoundTrip implements http.RoundTripper.
+func (s *Stub) RoundTrip(request *http.Request) (*http.Response, error) {
+	var body struct {
+		Method string                 `json:"method"`
+		Params map[string]interface{} `json:"params"`
+	}
+	if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	request.Body.Close()
+
+	s.mu.Lock()
+	s.calls = append(s.calls, Call{Method: body.Method, Params: body.Params})
+	handler, ok := s.handlers[body.Method]
+	s.mu.Unlock()
+
+	response := Error(2400, "No stub for "+body.Method)
+	if ok {
+		response = handler(body.Params)
+	}
+
+	responseBody, err := json.Marshal(response)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Response{
+		Status:     "200 OK",
+		StatusCode: http.StatusOK,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{"Content-Type": []string{"application/json; charset=UTF-8"}},
+		Body:       io.NopCloser(bytes.NewReader(responseBody)),
+		Request:    request,
+	}, nil
+}
+
+// Client returns an api client sending all calls to the stub. The session cookies are stored in a temporary
+// directory of the test.
+func (s *Stub) Client(t testing.TB) *api.Client {
+	t.Helper()
+
+	baseURL, _ := url.Parse("https://api.stub.invalid/jsonrpc/")
+	client, err := api.NewClient(api.ClientOptions{
+		BaseURL:          baseURL,
+		Transport:        s,
+		SessionCachePath: filepath.Join(t.TempDir(), "cookies"),
+	})
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+	client.DefaultTTL = 3600
+
+	return client
+}
+
+// Success returns a successful response with resData.
+func Success(resData map[string]interface{}) map[string]interface{} {
+	response := map[string]interface{}{
+		"code": 1000,
+		"msg":  "Command completed successfully",
+	}
+	if resData != nil {
+		response["resData"] = resData
+	}
+	return response
+}
+
+// Pending returns a successful response of a command that is not completed yet.
+func Pending(resData map[string]interface{}) map[string]interface{} {
+	response := Success(resData)
+	response["code"] = 1001
+	response["msg"] = "Command completed successfully; action pending"
+	return response
+}
+
+// Error returns an error response with code and msg.
+func Error(code int, msg string) map[string]interface{} {
+	return map[string]interface{}{
+		"code": code,
+		"msg":  msg,
+	}
+}
@@ -2,15 +2,20 @@ package api
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/go-logr/logr"
 	cookiejar "github.com/orirawlings/persistent-cookiejar"
 	"github.com/pkg/errors"
+	"io"
+	"mime"
 	"net/http"
 	"net/url"
+	"strings"
 	"sync"
+	"time"
 )
 
 const (
@@ -18,16 +23,106 @@ const (
 	COMMAND_SUCCESSFUL_PENDING float64 = 1001
 )
 
+// sensitiveKeys are masked in debug output and error messages
+var sensitiveKeys = map[string]bool{
+	"pass":     true,
+	"password": true,
+	"pw":       true,
+	"tan":      true,
+	"authCode": true,
+}
+
+// redact returns a copy of value with the values of all sensitive keys masked.
+func redact(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		redacted := make(map[string]interface{}, len(typed))
+		for key, val := range typed {
+			if sensitiveKeys[key] {
+				redacted[key] = "***"
+			} else {
+				redacted[key] = redact(val)
+			}
+		}
+		return redacted
+	case Response:
+		return redact(map[string]interface{}(typed))
+	case []interface{}:
+		redacted := make([]interface{}, len(typed))
+		for i, val := range typed {
+			redacted[i] = redact(val)
+		}
+		return redacted
+	default:
+		return value
+	}
+}
+
+func redactedJson(value interface{}) string {
+	jsonStr, err := json.Marshal(redact(value))
+	if err != nil {
+		return fmt.Sprintf("could not marshal: %v", err)
+	}
+	return string(jsonStr)
+}
+
 type Response map[string]interface{}
 
+// Code returns the status code of the response or 0 if the response has no numerical code. Responses returned by
+// Call always have a code.
 func (r Response) Code() float64 {
-	return r["code"].(float64)
+	code, _ := r["code"].(float64)
+	return code
+}
+
+// IsSuccess reports whether the command was successful, either completed (1000) or pending (1001).
+func (r Response) IsSuccess() bool {
+	return r.Code() == COMMAND_SUCCESSFUL || r.Code() == COMMAND_SUCCESSFUL_PENDING
+}
+
+// IsPending reports whether the command was accepted, but is not completed yet (1001).
+func (r Response) IsPending() bool {
+	return r.Code() == COMMAND_SUCCESSFUL_PENDING
+}
+
+// ErrorMessage returns a human-readable description of an api error, e.g. "Object exists (code 2302)".
+// If the response does not contain an error message, the whole response is returned.
+func (r Response) ErrorMessage() string {
+	msg, _ := r["msg"].(string)
+	reason, _ := r["reason"].(string)
+	code, hasCode := r["code"].(float64)
+	if msg == "" && !hasCode {
+		return r.ApiError()
+	}
+
+	message := msg
+	if hasCode {
+		message = strings.TrimSpace(fmt.Sprintf("%s (code %d)", msg, int(code)))
+	}
+	if reason != "" {
+		message += ": " + reason
+	}
+	return message
+}
+
+// IsTruthy interprets the different representations of booleans returned by the api, e.g. true, 1 or "1".
+func IsTruthy(value interface{}) bool {
+	switch typed := value.(type) {
+	case bool:
+		return typed
+	case float64:
+		return typed != 0
+	case string:
+		return typed == "1" || strings.EqualFold(typed, "true")
+	default:
+		return false
+	}
 }
 
 func (r Response) ApiError() string {
 	jsonStr, err := json.Marshal(r)
 	if err != nil {
-		return fmt.Sprintf("could not parse error: %w", err)
+		return fmt.Sprintf("could not parse error: %v", err)
 	}
 	return string(jsonStr)
 }
@@ -39,41 +134,107 @@ type Client struct {
 	Username   string
 	Password   string
 	Debug      bool
-	jar        *cookiejar.Jar
-	mu         sync.Mutex
+	// DefaultTTL is the TTL of nameserver records that do not set a ttl
+	DefaultTTL int
+	// Customer is the id of a sub customer of a reseller account, the calls of customerMethods are executed for it
+	Customer string
+	// Testing executes all modifying calls in testing mode, in which the api validates them without changes
+	Testing bool
+	jar     *cookiejar.Jar
+	// jarMu serializes saving the cookie jar, calls are otherwise executed in parallel
+	jarMu sync.Mutex
+
+	nameserverInfoCache map[string]*nameserverInfoEntry
+	nameserverInfoMu    sync.Mutex
+
+	testingZones   map[string]bool
+	testingZonesMu sync.Mutex
+
+	reservedRecords   map[string][]ReservedRecord
+	replacedRecords   map[string]bool
+	reservedRecordsMu sync.Mutex
 }
 
-func NewClient(username string, password string, baseURL *url.URL, logger *logr.Logger, debug bool) (*Client, error) {
+// ClientOptions configures a Client created with NewClient.
+type ClientOptions struct {
+	Username string
+	Password string
+	BaseURL  *url.URL
+	// Logger receives the debug output, it is discarded if nil
+	Logger  *logr.Logger
+	Debug   bool
+	Timeout time.Duration
+	// ProxyURL overrides the proxy taken from the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables
+	ProxyURL *url.URL
+	// Compression requests gzip encoded responses
+	Compression bool
+	// Transport is used for all requests instead of the default transport, e.g. to answer requests with a stub in
+	// tests. ProxyURL and Compression are ignored if it is set.
+	Transport http.RoundTripper
+	// SessionCachePath is the file the session cookies are stored in, the default file of the cookie jar if empty
+	SessionCachePath string
+}
+
+// NewClient creates a new api client.
+func NewClient(options ClientOptions) (*Client, error) {
+	logger := options.Logger
+	if logger == nil {
+		discard := logr.Discard()
+		logger = &discard
+	}
 	logger.V(10).Info("initializing new http client")
 
 	jar, err := cookiejar.New(&cookiejar.Options{
+		Filename:              options.SessionCachePath,
 		PersistSessionCookies: true,
 	})
 	if err != nil {
 		return nil, errors.WithStack(fmt.Errorf("could not create http client cookie jar: %w", err))
 	}
 
+	transport := options.Transport
+	if transport == nil {
+		proxy := http.ProxyFromEnvironment
+		if options.ProxyURL != nil {
+			proxy = http.ProxyURL(options.ProxyURL)
+		}
+
+		transport = &http.Transport{
+			Proxy:              proxy,
+			DisableCompression: !options.Compression,
+		}
+	}
+
 	httpClient := &http.Client{
-		Transport: &http.Transport{
-			DisableCompression: true,
-		},
-		Jar: jar,
+		Transport: transport,
+		Jar:       jar,
+		Timeout:   options.Timeout,
 	}
 
 	return &Client{
 		httpClient: httpClient,
 		logger:     logger,
-		BaseURL:    baseURL,
-		Username:   username,
-		Password:   password,
-		Debug:      debug,
+		BaseURL:    options.BaseURL,
+		Username:   options.Username,
+		Password:   options.Password,
+		Debug:      options.Debug,
 		jar:        jar,
+
+		nameserverInfoCache: map[string]*nameserverInfoEntry{},
+		testingZones:        map[string]bool{},
+		reservedRecords:     map[string][]ReservedRecord{},
+		replacedRecords:     map[string]bool{},
 	}, nil
 }
 
 func (c *Client) _Call(ctx context.Context, method string, parameters map[string]interface{}, expectResponseBody bool) (Response, error) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	// other calls like account.login always refer to the account of the credentials
+	if c.Customer != "" && customerMethods[method] {
+		parameters = withParameter(parameters, "customer", c.Customer)
+	}
+	if c.Testing && isModifyingMethod(method) {
+		parameters = withParameter(parameters, "testing", true)
+	}
 
 	requestBody := map[string]interface{}{}
 	requestBody["method"] = method
@@ -84,13 +245,8 @@ func (c *Client) _Call(ctx context.Context, method string, parameters map[string
 	}
 
 	if c.Debug {
-		fmt.Printf("Request (%s): %s", method, requestJsonBody)
-		c.logger.Info(fmt.Sprintf("Request (%s): %s", method, requestJsonBody))
-	}
-
-	err = c.jar.Save()
-	if err != nil {
-		return nil, errors.WithStack(fmt.Errorf("could not save cookies: %w", err))
+		fmt.Printf("Request (%s): %s", method, redactedJson(requestBody))
+		c.logger.Info(fmt.Sprintf("Request (%s): %s", method, redactedJson(requestBody)))
 	}
 
 	request, err := http.NewRequest("POST", c.BaseURL.String(), bytes.NewReader(requestJsonBody))
@@ -104,23 +260,59 @@ func (c *Client) _Call(ctx context.Context, method string, parameters map[string
 	if err != nil {
 		return nil, errors.WithStack(fmt.Errorf("could not execute rpc request: %w", err))
 	}
+	defer post.Body.Close()
+
+	// the transport only decodes gzip transparently if it requested it, i.e. with compression enabled
+	var bodyReader io.Reader = post.Body
+	if !post.Uncompressed && strings.EqualFold(post.Header.Get("content-encoding"), "gzip") {
+		gzipReader, err := gzip.NewReader(post.Body)
+		if err != nil {
+			return nil, errors.WithStack(fmt.Errorf("could not decompress rpc response: %w, %s", err, post.Status))
+		}
+		defer gzipReader.Close()
+		bodyReader = gzipReader
+	}
+
+	body, err := io.ReadAll(bodyReader)
+	if err != nil {
+		return nil, errors.WithStack(fmt.Errorf("could not read rpc response: %w, %s", err, post.Status))
+	}
+
+	// gateways and maintenance pages answer with html instead of a rpc response
+	if post.StatusCode < 200 || post.StatusCode > 299 {
+		return nil, errors.WithStack(fmt.Errorf("rpc request failed with http status %s: %s", post.Status, bodySnippet(body)))
+	}
+	mediaType, _, _ := mime.ParseMediaType(post.Header.Get("content-type"))
+	if expectResponseBody && mediaType != "" && !strings.HasSuffix(mediaType, "json") {
+		return nil, errors.WithStack(fmt.Errorf("rpc response has unexpected content type %s (http status %s): %s", mediaType, post.Status, bodySnippet(body)))
+	}
 
 	var response map[string]interface{}
 	if expectResponseBody { // not all requests return a response
-		err = json.NewDecoder(post.Body).Decode(&response)
+		err = json.Unmarshal(body, &response)
 		if err != nil {
-			return nil, errors.WithStack(fmt.Errorf("could not unmarshal rpc response to json: %w, %s, %s, %s", err, requestJsonBody, c.BaseURL.String(), post.Status))
+			return nil, errors.WithStack(fmt.Errorf("could not unmarshal rpc response to json: %w, %s, %s, %s", err, redactedJson(requestBody), c.BaseURL.String(), post.Status))
 		}
 
 		// Make sure body is valid json before debug message
 		if c.Debug {
-			c.logger.Info(fmt.Sprintf("Request (%s): %s", method, post.Body))
+			c.logger.Info(fmt.Sprintf("Response (%s): %s", method, redactedJson(response)))
+		}
+
+		// every rpc response has a status code, a response without it is no answer of the api, e.g. of a proxy
+		if _, ok := response["code"].(float64); !ok {
+			return nil, errors.WithStack(fmt.Errorf("rpc response contains no status code (http status %s): %s", post.Status, bodySnippet(body)))
 		}
 	}
 
-	err = c.jar.Save()
-	if err != nil {
-		return nil, errors.WithStack(fmt.Errorf("could not save cookies: %w", err))
+	// the cookies only change with a Set-Cookie header, e.g. of account.login
+	if len(post.Header.Values("Set-Cookie")) > 0 {
+		c.jarMu.Lock()
+		err = c.jar.Save()
+		c.jarMu.Unlock()
+		if err != nil {
+			return nil, errors.WithStack(fmt.Errorf("could not save cookies: %w", err))
+		}
 	}
 
 	if expectResponseBody {
@@ -129,6 +321,87 @@ func (c *Client) _Call(ctx context.Context, method string, parameters map[string
 	return nil, nil
 }
 
+// customerMethods are the api methods on objects of a customer, which accept the customer parameter. Methods of the
+// reseller account itself like account.*, accounting.*, message.* and domain.getPrices do not.
+var customerMethods = map[string]bool{
+	"contact.create":          true,
+	"contact.delete":          true,
+	"contact.info":            true,
+	"contact.list":            true,
+	"contact.update":          true,
+	"dnssec.adddnskey":        true,
+	"dnssec.deletednskey":     true,
+	"dnssec.disablednssec":    true,
+	"dnssec.enablednssec":     true,
+	"dnssec.info":             true,
+	"dnssec.listkeys":         true,
+	"domain.create":           true,
+	"domain.delete":           true,
+	"domain.info":             true,
+	"domain.list":             true,
+	"domain.renew":            true,
+	"domain.trade":            true,
+	"domain.update":           true,
+	"host.create":             true,
+	"host.delete":             true,
+	"host.info":               true,
+	"host.update":             true,
+	"nameserver.create":       true,
+	"nameserver.createRecord": true,
+	"nameserver.delete":       true,
+	"nameserver.deleteRecord": true,
+	"nameserver.info":         true,
+	"nameserver.list":         true,
+	"nameserver.update":       true,
+	"nameserver.updateRecord": true,
+	"nsset.create":            true,
+	"nsset.delete":            true,
+	"nsset.info":              true,
+	"nsset.update":            true,
+}
+
+// modifyingActions are the prefixes of the actions of api methods that change data, e.g. createRecord of
+// nameserver.createRecord. Only these methods support the testing mode.
+var modifyingActions = []string{"create", "update", "delete", "renew", "transfer", "trade", "restore", "enable", "disable", "add", "remove"}
+
+// isModifyingMethod reports whether an api method changes data. account.* methods are never modifying, as the
+// session itself cannot be tested.
+func isModifyingMethod(method string) bool {
+	object, action, ok := strings.Cut(method, ".")
+	if !ok || object == "account" {
+		return false
+	}
+
+	action = strings.ToLower(action)
+	for _, prefix := range modifyingActions {
+		if strings.HasPrefix(action, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// withParameter returns a copy of parameters with key set to value, the parameters of the caller are not changed.
+func withParameter(parameters map[string]interface{}, key string, value interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(parameters)+1)
+	for k, v := range parameters {
+		result[k] = v
+	}
+	result[key] = value
+	return result
+}
+
+// bodySnippet returns the beginning of a response body for error messages.
+func bodySnippet(body []byte) string {
+	const maxLength = 512
+
+	snippet := strings.TrimSpace(string(body))
+	if len(snippet) > maxLength {
+		snippet = snippet[:maxLength] + "..."
+	}
+	return snippet
+}
+
 func (c *Client) CallNoParams(ctx context.Context, method string) (Response, error) {
 	return c.Call(ctx, method, map[string]interface{}{})
 }
@@ -0,0 +1,348 @@
+package api
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	cookiejar "github.com/orirawlings/persistent-cookiejar"
+)
+
+// newTestClient returns a client sending all calls to a test server answering with handler.
+func newTestClient(t *testing.T, handler http.HandlerFunc) (*Client, *httptest.Server) {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	baseURL, _ := url.Parse(server.URL)
+	client, err := NewClient(ClientOptions{
+		BaseURL:          baseURL,
+		SessionCachePath: filepath.Join(t.TempDir(), "cookies"),
+	})
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+
+	return client, server
+}
+
+// decodeRequest returns the method and parameters of a rpc request.
+func decodeRequest(t *testing.T, r *http.Request) (string, map[string]interface{}) {
+	t.Helper()
+
+	var body struct {
+		Method string                 `json:"method"`
+		Params map[string]interface{} `json:"params"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		t.Errorf("could not decode request: %v", err)
+	}
+	return body.Method, body.Params
+}
+
+func writeJson(w http.ResponseWriter, body string) {
+	w.Header().Set("content-type", "application/json")
+	fmt.Fprint(w, body)
+}
+
+func TestCallReturnsResponse(t *testing.T) {
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		method, params := decodeRequest(t, r)
+		if method != "domain.info" || params["domain"] != "example.com" {
+			t.Errorf("unexpected request %s %v", method, params)
+		}
+		writeJson(w, `{"code":1000,"msg":"Command completed successfully","resData":{"domain":"example.com","roId":42}}`)
+	})
+
+	call, err := client.Call(context.Background(), "domain.info", map[string]interface{}{"domain": "example.com"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !call.IsSuccess() || call.IsPending() {
+		t.Errorf("expected completed response, got %s", call.ApiError())
+	}
+	resData := call["resData"].(map[string]interface{})
+	if resData["domain"] != "example.com" || resData["roId"] != float64(42) {
+		t.Errorf("unexpected resData %v", resData)
+	}
+}
+
+func TestCallMalformedResponses(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		contentType string
+		body        string
+		wantError   string
+	}{
+		{
+			name:        "html error page",
+			status:      http.StatusBadGateway,
+			contentType: "text/html",
+			body:        "<html><body>Bad Gateway</body></html>",
+			wantError:   "http status 502 Bad Gateway: <html><body>Bad Gateway</body></html>",
+		},
+		{
+			name:        "html maintenance page with status ok",
+			status:      http.StatusOK,
+			contentType: "text/html; charset=utf-8",
+			body:        "<html><body>Maintenance</body></html>",
+			wantError:   "unexpected content type text/html",
+		},
+		{
+			name:        "invalid json",
+			status:      http.StatusOK,
+			contentType: "application/json",
+			body:        `{"code":1000,`,
+			wantError:   "could not unmarshal rpc response to json",
+		},
+		{
+			name:        "json without code",
+			status:      http.StatusOK,
+			contentType: "application/json",
+			body:        `{"error":"proxy authentication required"}`,
+			wantError:   "rpc response contains no status code",
+		},
+		{
+			name:        "json with string code",
+			status:      http.StatusOK,
+			contentType: "application/json",
+			body:        `{"code":"1000"}`,
+			wantError:   "rpc response contains no status code",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("content-type", test.contentType)
+				w.WriteHeader(test.status)
+				fmt.Fprint(w, test.body)
+			})
+
+			_, err := client.Call(context.Background(), "nameserver.info", map[string]interface{}{"domain": "example.com"})
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), test.wantError) {
+				t.Errorf("expected error containing %q, got %q", test.wantError, err.Error())
+			}
+		})
+	}
+}
+
+func TestCallApiError(t *testing.T) {
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJson(w, `{"code":2303,"msg":"Object does not exist","reason":"Domain not found"}`)
+	})
+
+	call, err := client.Call(context.Background(), "domain.info", map[string]interface{}{"domain": "example.com"})
+	if err != nil {
+		t.Fatalf("api errors are no call errors, got %v", err)
+	}
+	if call.IsSuccess() {
+		t.Error("expected no success")
+	}
+	if got, want := call.ErrorMessage(), "Object does not exist (code 2303): Domain not found"; got != want {
+		t.Errorf("expected error message %q, got %q", want, got)
+	}
+}
+
+// failingTransport fails all requests with err.
+type failingTransport struct {
+	err error
+}
+
+func (f failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, f.err
+}
+
+func TestCallWrapsTransportError(t *testing.T) {
+	transportErr := errors.New("connection refused")
+	baseURL, _ := url.Parse("https://api.stub.invalid/jsonrpc/")
+	client, err := NewClient(ClientOptions{
+		BaseURL:          baseURL,
+		Transport:        failingTransport{err: transportErr},
+		SessionCachePath: filepath.Join(t.TempDir(), "cookies"),
+	})
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+
+	_, err = client.Call(context.Background(), "domain.info", map[string]interface{}{"domain": "example.com"})
+	if !errors.Is(err, transportErr) {
+		t.Errorf("expected the transport error to be wrapped, got %v", err)
+	}
+}
+
+func TestCallConcurrentlySavesCookies(t *testing.T) {
+	cookiePath := filepath.Join(t.TempDir(), "cookies")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, params := decodeRequest(t, r)
+		http.SetCookie(w, &http.Cookie{Name: fmt.Sprintf("session%v", params["n"]), Value: "1", Path: "/"})
+		writeJson(w, `{"code":1000,"msg":"Command completed successfully"}`)
+	}))
+	t.Cleanup(server.Close)
+
+	baseURL, _ := url.Parse(server.URL)
+	client, err := NewClient(ClientOptions{
+		BaseURL:          baseURL,
+		SessionCachePath: cookiePath,
+	})
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+
+	const calls = 20
+	var wg sync.WaitGroup
+	for n := 0; n < calls; n++ {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			if _, err := client.Call(context.Background(), "account.info", map[string]interface{}{"n": n}); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}(n)
+	}
+	wg.Wait()
+
+	jar, err := cookiejar.New(&cookiejar.Options{Filename: cookiePath, PersistSessionCookies: true})
+	if err != nil {
+		t.Fatalf("could not load saved cookies: %v", err)
+	}
+	if got := len(jar.Cookies(baseURL)); got != calls {
+		t.Errorf("expected %d saved cookies, got %d", calls, got)
+	}
+}
+
+func TestCallGzipResponse(t *testing.T) {
+	for _, compression := range []bool{false, true} {
+		t.Run(fmt.Sprintf("compression %v", compression), func(t *testing.T) {
+			// some proxies compress responses even if the client did not ask for it
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("content-type", "application/json")
+				w.Header().Set("content-encoding", "gzip")
+				gzipWriter := gzip.NewWriter(w)
+				fmt.Fprint(gzipWriter, `{"code":1000,"msg":"Command completed successfully","resData":{"domain":"example.com"}}`)
+				gzipWriter.Close()
+			}))
+			t.Cleanup(server.Close)
+
+			baseURL, _ := url.Parse(server.URL)
+			client, err := NewClient(ClientOptions{
+				BaseURL:          baseURL,
+				Compression:      compression,
+				SessionCachePath: filepath.Join(t.TempDir(), "cookies"),
+			})
+			if err != nil {
+				t.Fatalf("could not create client: %v", err)
+			}
+
+			call, err := client.Call(context.Background(), "domain.info", map[string]interface{}{"domain": "example.com"})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resData, _ := call["resData"].(map[string]interface{}); !call.IsSuccess() || resData["domain"] != "example.com" {
+				t.Errorf("unexpected response %v", call)
+			}
+		})
+	}
+}
+
+func TestCallInvalidGzipResponse(t *testing.T) {
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-encoding", "gzip")
+		writeJson(w, `{"code":1000}`)
+	})
+
+	_, err := client.Call(context.Background(), "domain.info", map[string]interface{}{"domain": "example.com"})
+	if err == nil || !strings.Contains(err.Error(), "could not decompress rpc response") {
+		t.Errorf("expected a decompression error, got %v", err)
+	}
+}
+
+func TestCallNoResponseBody(t *testing.T) {
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	if err := client.CallNoResponseBody(context.Background(), "host.delete", map[string]interface{}{"roId": 1}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestCallCustomer(t *testing.T) {
+	// the calls are made one after another
+	customers := map[string]interface{}{}
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		method, params := decodeRequest(t, r)
+		customers[method] = params["customer"]
+		writeJson(w, `{"code": 1000}`)
+	})
+	client.Customer = "4711"
+
+	tests := map[string]bool{
+		"account.login":             false,
+		"account.info":              false,
+		"account.logout":            false,
+		"accounting.accountBalance": false,
+		"message.poll":              false,
+		"message.ack":               false,
+		"domain.getPrices":          false,
+		"domain.create":             true,
+		"domain.info":               true,
+		"domain.list":               true,
+		"contact.update":            true,
+		"host.create":               true,
+		"nsset.info":                true,
+		"nameserver.info":           true,
+		"nameserver.createRecord":   true,
+		"dnssec.listkeys":           true,
+	}
+
+	for method, want := range tests {
+		if _, err := client.Call(context.Background(), method, map[string]interface{}{}); err != nil {
+			t.Fatalf("unexpected error of %s: %v", method, err)
+		}
+		if got := customers[method]; (got == "4711") != want {
+			t.Errorf("expected customer of %s: %t, got %v", method, want, got)
+		}
+	}
+}
+
+func TestResponseCodeWithoutCode(t *testing.T) {
+	response := Response{"msg": "no code"}
+	if response.Code() != 0 || response.IsSuccess() {
+		t.Errorf("expected a response without code to be no success")
+	}
+}
+
+func TestIsTruthy(t *testing.T) {
+	tests := map[interface{}]bool{
+		true:       true,
+		false:      false,
+		float64(1): true,
+		float64(0): false,
+		"1":        true,
+		"0":        false,
+		"TRUE":     true,
+		"false":    false,
+		"":         false,
+		nil:        false,
+	}
+	for value, want := range tests {
+		if got := IsTruthy(value); got != want {
+			t.Errorf("IsTruthy(%#v) = %v, want %v", value, got, want)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"context"
+)
+
+// listPageLimit is the number of items requested per page by CallAll
+const listPageLimit = 250
+
+// CallAll calls a paged list method like domain.list until all pages are fetched and returns the concatenated
+// items of resData[listKey] together with the response of the last page. If the api answers a page with an error,
+// the failed response is returned and callers have to check it with IsSuccess before using the items.
+func (c *Client) CallAll(ctx context.Context, method string, parameters map[string]interface{}, listKey string) ([]interface{}, Response, error) {
+	var items []interface{}
+
+	for page := 1; ; page++ {
+		pageParameters := make(map[string]interface{}, len(parameters)+2)
+		for key, value := range parameters {
+			pageParameters[key] = value
+		}
+		pageParameters["page"] = page
+		pageParameters["pagelimit"] = listPageLimit
+
+		call, err := c.Call(ctx, method, pageParameters)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !call.IsSuccess() {
+			return nil, call, nil
+		}
+
+		resData, _ := call["resData"].(map[string]interface{})
+		pageItems, _ := resData[listKey].([]interface{})
+		items = append(items, pageItems...)
+
+		// count is the total number of items, without it a short page is the last one
+		count, hasCount := resData["count"].(float64)
+		if len(pageItems) == 0 || (hasCount && len(items) >= int(count)) || (!hasCount && len(pageItems) < listPageLimit) {
+			return items, call, nil
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestCallAllFetchesAllPages(t *testing.T) {
+	var pages []interface{}
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		method, params := decodeRequest(t, r)
+		if method != "domain.list" || params["domain"] != "*.de" || params["pagelimit"] != float64(listPageLimit) {
+			t.Errorf("unexpected request %s %v", method, params)
+		}
+		pages = append(pages, params["page"])
+
+		// the first page is full, the second one contains the remaining item
+		switch params["page"] {
+		case float64(1):
+			items := ""
+			for i := 0; i < listPageLimit; i++ {
+				if i > 0 {
+					items += ","
+				}
+				items += fmt.Sprintf(`{"domain":"example%d.de"}`, i)
+			}
+			writeJson(w, `{"code":1000,"resData":{"count":251,"domain":[`+items+`]}}`)
+		default:
+			writeJson(w, `{"code":1000,"resData":{"count":251,"domain":[{"domain":"last.de"}]}}`)
+		}
+	})
+
+	items, call, err := client.CallAll(context.Background(), "domain.list", map[string]interface{}{"domain": "*.de"}, "domain")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !call.IsSuccess() {
+		t.Fatalf("expected success, got %s", call.ErrorMessage())
+	}
+	if len(pages) != 2 || pages[0] != float64(1) || pages[1] != float64(2) {
+		t.Errorf("expected pages 1 and 2 to be fetched, got %v", pages)
+	}
+	if len(items) != listPageLimit+1 {
+		t.Fatalf("expected %d items, got %d", listPageLimit+1, len(items))
+	}
+	if last := items[len(items)-1].(map[string]interface{}); last["domain"] != "last.de" {
+		t.Errorf("expected the item of the second page last, got %v", last)
+	}
+}
+
+func TestCallAllApiError(t *testing.T) {
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, params := decodeRequest(t, r)
+		if params["page"] == float64(1) {
+			writeJson(w, `{"code":1000,"resData":{"count":300,"domain":[{"domain":"example.de"}]}}`)
+			return
+		}
+		writeJson(w, `{"code":2400,"msg":"Command failed"}`)
+	})
+
+	// a page shorter than the count is no last page
+	items, call, err := client.CallAll(context.Background(), "domain.list", nil, "domain")
+	if err != nil {
+		t.Fatalf("api errors are no call errors, got %v", err)
+	}
+	if call.IsSuccess() {
+		t.Error("expected the failed response of the second page")
+	}
+	if items != nil {
+		t.Errorf("expected no items, got %v", items)
+	}
+}
+
+func TestCallAllEmptyList(t *testing.T) {
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJson(w, `{"code":1000,"resData":{"count":0}}`)
+	})
+
+	items, call, err := client.CallAll(context.Background(), "contact.list", nil, "contact")
+	if err != nil || !call.IsSuccess() {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(items) != 0 {
+		t.Errorf("expected no items, got %v", items)
+	}
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"context"
+	"sync"
+)
+
+// nameserverInfoEntry holds a single nameserver.info response. The once guards concurrent reads of the
+// same zone, so that only one request is sent even if many records are refreshed in parallel.
+type nameserverInfoEntry struct {
+	once     sync.Once
+	response Response
+	err      error
+}
+
+// NameserverInfo returns the nameserver.info response of a zone. Successful responses are cached for the
+// lifetime of the client, which is a single terraform operation, so that reading many records of the same
+// zone does not fetch the whole zone once per record. Call InvalidateNameserverInfo after modifying the zone.
+func (c *Client) NameserverInfo(ctx context.Context, domain string) (Response, error) {
+	c.nameserverInfoMu.Lock()
+	entry, ok := c.nameserverInfoCache[domain]
+	if !ok {
+		entry = &nameserverInfoEntry{}
+		c.nameserverInfoCache[domain] = entry
+	}
+	c.nameserverInfoMu.Unlock()
+
+	entry.once.Do(func() {
+		entry.response, entry.err = c.Call(ctx, "nameserver.info", map[string]interface{}{
+			"domain": domain,
+		})
+	})
+
+	if entry.err != nil || entry.response.Code() != COMMAND_SUCCESSFUL {
+		// do not keep failed responses around, the next read should try again
+		c.InvalidateNameserverInfo(domain)
+	}
+
+	return entry.response, entry.err
+}
+
+// InvalidateNameserverInfo drops the cached nameserver.info response of a zone.
+func (c *Client) InvalidateNameserverInfo(domain string) {
+	c.nameserverInfoMu.Lock()
+	defer c.nameserverInfoMu.Unlock()
+
+	delete(c.nameserverInfoCache, domain)
+}
@@ -0,0 +1,56 @@
+package api
+
+// ReservedRecord is a nameserver record that is created by the client.
+type ReservedRecord struct {
+	// Name is the fully qualified name of the record
+	Name string
+	Type string
+}
+
+// ReserveRecord reserves the name and type of a record before it is created in the zone of domain. If a record
+// reserved before with this client conflicts with it, nothing is reserved and the conflicting record is returned.
+// As a client lives for a single terraform operation, this finds records that are created in the same apply.
+func (c *Client) ReserveRecord(domain string, record ReservedRecord, conflicts func(ReservedRecord) bool) (ReservedRecord, bool) {
+	c.reservedRecordsMu.Lock()
+	defer c.reservedRecordsMu.Unlock()
+
+	for _, reserved := range c.reservedRecords[domain] {
+		if conflicts(reserved) {
+			return reserved, false
+		}
+	}
+	c.reservedRecords[domain] = append(c.reservedRecords[domain], record)
+
+	return record, true
+}
+
+// ReleaseRecord drops the reservation of a record, e.g. after it could not be created.
+func (c *Client) ReleaseRecord(domain string, record ReservedRecord) {
+	c.reservedRecordsMu.Lock()
+	defer c.reservedRecordsMu.Unlock()
+
+	records := c.reservedRecords[domain]
+	for i, reserved := range records {
+		if reserved == record {
+			c.reservedRecords[domain] = append(records[:i:i], records[i+1:]...)
+			return
+		}
+	}
+}
+
+// SetReplacedRecord records that the record with the resource id is replaced in the current plan. It is deleted
+// before its replacement is created and so does not conflict with the records planned at its name.
+func (c *Client) SetReplacedRecord(id string) {
+	c.reservedRecordsMu.Lock()
+	defer c.reservedRecordsMu.Unlock()
+
+	c.replacedRecords[id] = true
+}
+
+// IsReplacedRecord reports whether the record with the resource id is replaced in the current plan.
+func (c *Client) IsReplacedRecord(id string) bool {
+	c.reservedRecordsMu.Lock()
+	defer c.reservedRecordsMu.Unlock()
+
+	return c.replacedRecords[id]
+}
@@ -0,0 +1,22 @@
+package api
+
+// SetTestingZone records whether a zone was created in testing mode, so that operations on records of the zone
+// can use the same mode.
+func (c *Client) SetTestingZone(domain string, testing bool) {
+	c.testingZonesMu.Lock()
+	defer c.testingZonesMu.Unlock()
+
+	if testing {
+		c.testingZones[domain] = true
+	} else {
+		delete(c.testingZones, domain)
+	}
+}
+
+// IsTestingZone reports whether a zone was created in testing mode.
+func (c *Client) IsTestingZone(domain string) bool {
+	c.testingZonesMu.Lock()
+	defer c.testingZonesMu.Unlock()
+
+	return c.testingZones[domain]
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PeriodRegexp matches registration periods like 1Y or 12M.
+var PeriodRegexp = regexp.MustCompile(`^([0-9]+)([YM])$`)
+
+// PeriodInMonths returns the length of a period like 1Y, 12M or a bare number of years in months.
+func PeriodInMonths(period string) (int, bool) {
+	period = strings.ToUpper(strings.TrimSpace(period))
+	if years, err := strconv.Atoi(period); err == nil {
+		return years * 12, true
+	}
+
+	match := PeriodRegexp.FindStringSubmatch(period)
+	if match == nil {
+		return 0, false
+	}
+	length, _ := strconv.Atoi(match[1])
+	if match[2] == "Y" {
+		return length * 12, true
+	}
+	return length, true
+}
+
+// TldPrice returns the price entry of a TLD from domain.getPrices. If the api answers with an error, the
+// response is returned together with a nil price so that callers can report the api error.
+func (c *Client) TldPrice(ctx context.Context, tld string) (map[string]interface{}, Response, error) {
+	parameters := map[string]interface{}{
+		"tld": []string{tld},
+	}
+
+	call, err := c.Call(ctx, "domain.getPrices", parameters)
+	if err != nil {
+		return nil, nil, err
+	}
+	if call.Code() != COMMAND_SUCCESSFUL {
+		return nil, call, nil
+	}
+
+	if resData, ok := call["resData"].(map[string]interface{}); ok {
+		if prices, ok := resData["price"].([]interface{}); ok {
+			for _, price := range prices {
+				if pricet, ok := price.(map[string]interface{}); ok && pricet["tld"] == tld {
+					return pricet, call, nil
+				}
+			}
+		}
+	}
+
+	return nil, call, errors.WithStack(fmt.Errorf("no price found for TLD '%s'. Got response: %s", tld, call.ApiError()))
+}
+
+// TldPeriods returns the registration periods of a price entry, sorted by length. The api returns them either as
+// list or as comma separated string.
+func TldPeriods(price map[string]interface{}) []string {
+	var periods []string
+
+	for _, key := range []string{"periods", "period"} {
+		switch typed := price[key].(type) {
+		case []interface{}:
+			for _, period := range typed {
+				periods = append(periods, strings.TrimSpace(fmt.Sprint(period)))
+			}
+		case string:
+			for _, period := range strings.Split(typed, ",") {
+				if period = strings.TrimSpace(period); period != "" {
+					periods = append(periods, period)
+				}
+			}
+		}
+		if len(periods) > 0 {
+			break
+		}
+	}
+
+	sort.SliceStable(periods, func(i, j int) bool {
+		left, _ := PeriodInMonths(periods[i])
+		right, _ := PeriodInMonths(periods[j])
+		return left < right
+	})
+
+	return periods
+}
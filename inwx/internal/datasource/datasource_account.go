@@ -0,0 +1,114 @@
+package datasource
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/inwx/terraform-provider-inwx/inwx/internal/api"
+)
+
+func AccountDataSource() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceAccountRead,
+		Schema: map[string]*schema.Schema{
+			"customer_id": {
+				Description: "Customer id of the account",
+				Type:        schema.TypeInt,
+				Computed:    true,
+			},
+			"balance": {
+				Description: "Total balance of the account",
+				Type:        schema.TypeFloat,
+				Computed:    true,
+			},
+			"available_balance": {
+				Description: "Balance of the account that is available for orders, i.e. without locked amounts",
+				Type:        schema.TypeFloat,
+				Computed:    true,
+			},
+			"currency": {
+				Description: "Currency of the balance",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"tan_required": {
+				Description: "Whether the account is protected by two factor authentication and needs a tan to unlock",
+				Type:        schema.TypeBool,
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func dataSourceAccountRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	client := m.(*api.Client)
+
+	call, err := client.CallNoParams(ctx, "account.info")
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not get account info",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	if call.Code() != api.COMMAND_SUCCESSFUL {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not get account info",
+			Detail:   fmt.Sprintf("API response not status code 1000. Got response: %s", call.ErrorMessage()),
+		})
+		return diags
+	}
+
+	account, _ := call["resData"].(map[string]any)
+	customerId, ok := account["customerId"].(float64)
+	if !ok {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not get account info",
+			Detail:   fmt.Sprintf("API response contains no customer id. Got response: %s", call.ApiError()),
+		})
+		return diags
+	}
+	d.Set("customer_id", int(customerId))
+	// tfa is "0" for accounts without two factor authentication, otherwise the kind of authentication
+	tfa := fmt.Sprint(account["tfa"])
+	d.Set("tan_required", account["tfa"] != nil && tfa != "" && tfa != "0")
+
+	call, err = client.CallNoParams(ctx, "accounting.accountBalance")
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not get account balance",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	if call.Code() != api.COMMAND_SUCCESSFUL {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not get account balance",
+			Detail:   fmt.Sprintf("API response not status code 1000. Got response: %s", call.ErrorMessage()),
+		})
+		return diags
+	}
+
+	balance, _ := call["resData"].(map[string]any)
+	if val, ok := balance["total"].(float64); ok {
+		d.Set("balance", val)
+	}
+	if val, ok := balance["available"].(float64); ok {
+		d.Set("available_balance", val)
+	}
+	if val, ok := balance["currency"].(string); ok {
+		d.Set("currency", val)
+	}
+
+	d.SetId(fmt.Sprint(int(customerId)))
+
+	return diags
+}
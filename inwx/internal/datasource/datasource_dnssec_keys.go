@@ -0,0 +1,164 @@
+package datasource
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/inwx/terraform-provider-inwx/inwx/internal/api"
+)
+
+func DNSSECKeysDataSource() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceDNSSECKeysRead,
+		Schema: map[string]*schema.Schema{
+			"domain": {
+				Description: "Name of the domain",
+				Type:        schema.TypeString,
+				Required:    true,
+			},
+			"keys": {
+				Description: "Active DNSSEC keys of the domain",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Description: "Id of the key",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"key_tag": {
+							Description: "Key tag",
+							Type:        schema.TypeInt,
+							Computed:    true,
+						},
+						"algorithm": {
+							Description: "Algorithm of the key",
+							Type:        schema.TypeInt,
+							Computed:    true,
+						},
+						"digest_type": {
+							Description: "Digest type",
+							Type:        schema.TypeInt,
+							Computed:    true,
+						},
+						"digest": {
+							Description: "Digest of the key",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"flag": {
+							Description: "Key flag (256=ZSK, 257=KSK)",
+							Type:        schema.TypeInt,
+							Computed:    true,
+						},
+						"public_key": {
+							Description: "Public key",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"status": {
+							Description: "DNSSEC status",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"ds": {
+							Description: "DS record data for the parent zone: <key_tag> <algorithm> <digest_type> <digest>",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceDNSSECKeysRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	client := m.(*api.Client)
+
+	domain := d.Get("domain").(string)
+
+	parameters := map[string]interface{}{
+		"domainName": domain,
+		"active":     1,
+	}
+
+	call, err := client.Call(ctx, "dnssec.listkeys", parameters)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not get DNSSEC keys",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	if call.Code() != api.COMMAND_SUCCESSFUL {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not get DNSSEC keys",
+			Detail:   fmt.Sprintf("API response not status code 1000. Got response: %s", call.ErrorMessage()),
+		})
+		return diags
+	}
+
+	keys, _ := call["resData"].([]interface{})
+
+	result := make([]map[string]interface{}, 0, len(keys))
+	for _, key := range keys {
+		keyt, ok := key.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		item := map[string]interface{}{
+			"id":          fmt.Sprint(keyt["id"]),
+			"key_tag":     dnssecKeyInt(keyt["keyTag"]),
+			"algorithm":   dnssecKeyInt(keyt["algorithmId"]),
+			"digest_type": dnssecKeyInt(keyt["digestTypeId"]),
+			"flag":        dnssecKeyInt(keyt["flagId"]),
+		}
+		if val, ok := keyt["digest"].(string); ok {
+			item["digest"] = val
+		}
+		if val, ok := keyt["publicKey"].(string); ok {
+			item["public_key"] = val
+		}
+		if val, ok := keyt["status"].(string); ok {
+			item["status"] = val
+		}
+		item["ds"] = fmt.Sprintf("%d %d %d %s", item["key_tag"], item["algorithm"], item["digest_type"], item["digest"])
+
+		result = append(result, item)
+	}
+
+	if err := d.Set("keys", result); err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not set keys",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	d.SetId(domain)
+
+	return diags
+}
+
+// dnssecKeyInt converts the numerical fields of dnssec.listkeys, which are returned as strings, to int.
+func dnssecKeyInt(value interface{}) int {
+	switch typed := value.(type) {
+	case string:
+		i, _ := strconv.Atoi(typed)
+		return i
+	case float64:
+		return int(typed)
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,169 @@
+package datasource
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/inwx/terraform-provider-inwx/inwx/internal/api"
+)
+
+// domainContactFields maps the attributes of a contact to the fields of contact.list
+var domainContactFields = map[string]string{
+	"type":           "type",
+	"name":           "name",
+	"organization":   "org",
+	"street_address": "street",
+	"city":           "city",
+	"postal_code":    "pc",
+	"state_province": "sp",
+	"country_code":   "cc",
+	"phone_number":   "voice",
+	"fax":            "fax",
+	"email":          "email",
+	"remarks":        "remarks",
+}
+
+func DomainContactsDataSource() *schema.Resource {
+	contactSchema := map[string]*schema.Schema{
+		"id": {
+			Description: "Id of the contact",
+			Type:        schema.TypeInt,
+			Computed:    true,
+		},
+	}
+	for attribute := range domainContactFields {
+		contactSchema[attribute] = &schema.Schema{
+			Description: "See the attribute of the same name of inwx_domain_contact",
+			Type:        schema.TypeString,
+			Computed:    true,
+		}
+	}
+
+	contactSchema["whois_protection"] = &schema.Schema{
+		Description: "Whether the contact data is hidden in the WHOIS",
+		Type:        schema.TypeBool,
+		Computed:    true,
+	}
+
+	return &schema.Resource{
+		ReadContext: dataSourceDomainContactsRead,
+		Schema: map[string]*schema.Schema{
+			"email": {
+				Description: "Only return contacts with this email address",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"name": {
+				Description: "Only return contacts with this name",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"organization": {
+				Description: "Only return contacts of this organization",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"contacts": {
+				Description: "Matching contacts",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: contactSchema,
+				},
+			},
+		},
+	}
+}
+
+func dataSourceDomainContactsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	client := m.(*api.Client)
+
+	filters := map[string]string{}
+	for _, attribute := range []string{"email", "name", "organization"} {
+		if value, ok := d.GetOk(attribute); ok {
+			filters[domainContactFields[attribute]] = value.(string)
+		}
+	}
+
+	// contact.list only supports a fuzzy search, the filters are matched exactly below
+	parameters := map[string]interface{}{}
+	for _, field := range []string{"email", "name", "org"} {
+		if value, ok := filters[field]; ok {
+			parameters["search"] = value
+			break
+		}
+	}
+
+	contacts, call, err := client.CallAll(ctx, "contact.list", parameters, "contact")
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not list contacts",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	if !call.IsSuccess() {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not list contacts",
+			Detail:   fmt.Sprintf("API response not status code 1000. Got response: %s", call.ErrorMessage()),
+		})
+		return diags
+	}
+
+	var ids []string
+	result := make([]map[string]interface{}, 0, len(contacts))
+	for _, contact := range contacts {
+		contactt, ok := contact.(map[string]any)
+		if !ok || !matchesContactFilters(contactt, filters) {
+			continue
+		}
+
+		item := map[string]interface{}{}
+		switch id := contactt["id"].(type) {
+		case float64:
+			item["id"] = int(id)
+		case string:
+			item["id"], _ = strconv.Atoi(id)
+		}
+		ids = append(ids, fmt.Sprint(item["id"]))
+		for attribute, field := range domainContactFields {
+			if value, ok := contactt[field].(string); ok {
+				item[attribute] = value
+			}
+		}
+		// the protection is returned as bool or as string, depending on the call
+		item["whois_protection"] = api.IsTruthy(contactt["protection"])
+		result = append(result, item)
+	}
+
+	if err := d.Set("contacts", result); err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not set contacts",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	d.SetId(fmt.Sprintf("contacts:%s", strings.Join(ids, ",")))
+
+	return diags
+}
+
+// matchesContactFilters reports whether all filters equal the fields of the contact, ignoring case.
+func matchesContactFilters(contact map[string]any, filters map[string]string) bool {
+	for field, value := range filters {
+		contactValue, _ := contact[field].(string)
+		if !strings.EqualFold(contactValue, value) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,62 @@
+package datasource
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/inwx/terraform-provider-inwx/inwx/internal/api/apitest"
+)
+
+func TestDataSourceDomainContactsRead(t *testing.T) {
+	stub := apitest.NewStub()
+	stub.Respond("contact.list", apitest.Success(map[string]interface{}{
+		"count": 4,
+		"contact": []interface{}{
+			map[string]interface{}{"id": 1, "name": "Erika Mustermann", "email": "erika@example.com", "protection": "1"},
+			map[string]interface{}{"id": "2", "name": "Erika Mustermann", "email": "ERIKA@example.com", "protection": true},
+			map[string]interface{}{"id": 3, "name": "Erika Mustermann", "email": "erika@example.com", "protection": "0"},
+			map[string]interface{}{"id": 4, "name": "Max Mustermann", "email": "max@example.com", "protection": "1"},
+		},
+	}))
+
+	d := DomainContactsDataSource().Data(nil)
+	d.Set("email", "erika@example.com")
+	diags := dataSourceDomainContactsRead(context.Background(), d, stub.Client(t))
+	for _, diagnostic := range diags {
+		if diagnostic.Severity == diag.Error {
+			t.Fatalf("unexpected error: %s: %s", diagnostic.Summary, diagnostic.Detail)
+		}
+	}
+
+	if params := stub.LastCall("contact.list"); params["search"] != "erika@example.com" {
+		t.Errorf("expected a search for the email, got %v", params)
+	}
+	if d.Id() != "contacts:1,2,3" {
+		t.Errorf("expected id contacts:1,2,3, got %s", d.Id())
+	}
+
+	contacts := d.Get("contacts").([]interface{})
+	if len(contacts) != 3 {
+		t.Fatalf("expected 3 contacts, got %v", contacts)
+	}
+	for i, want := range []bool{true, true, false} {
+		contact := contacts[i].(map[string]interface{})
+		if contact["whois_protection"] != want {
+			t.Errorf("expected whois_protection %v of contact %v, got %v", want, contact["id"], contact["whois_protection"])
+		}
+	}
+}
+
+func TestDataSourceDomainContactsReadApiError(t *testing.T) {
+	stub := apitest.NewStub()
+	stub.Respond("contact.list", apitest.Error(2400, "Command failed"))
+
+	d := DomainContactsDataSource().Data(nil)
+	if diags := dataSourceDomainContactsRead(context.Background(), d, stub.Client(t)); !diags.HasError() {
+		t.Fatal("expected error")
+	}
+	if d.Id() != "" {
+		t.Errorf("expected no id after a failed read, got %s", d.Id())
+	}
+}
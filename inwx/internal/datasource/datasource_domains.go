@@ -0,0 +1,135 @@
+package datasource
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/inwx/terraform-provider-inwx/inwx/internal/api"
+)
+
+func DomainsDataSource() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceDomainsRead,
+		Schema: map[string]*schema.Schema{
+			"tld": {
+				Description: "Only return domains of this TLD, e.g. com",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"status": {
+				Description: "Only return domains with this status, e.g. OK",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"names": {
+				Description: "Names of the domains",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"domains": {
+				Description: "Domains of the account",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Description: "Name of the domain",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"status": {
+							Description: "Status of the domain",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"expiration_date": {
+							Description: "Expiration date of the domain",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceDomainsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	client := m.(*api.Client)
+
+	tld := strings.ToLower(strings.TrimPrefix(d.Get("tld").(string), "."))
+	status := d.Get("status").(string)
+
+	parameters := map[string]interface{}{}
+	if tld != "" {
+		parameters["domain"] = "*." + tld
+	}
+	if status != "" {
+		parameters["status"] = status
+	}
+
+	domains, call, err := client.CallAll(ctx, "domain.list", parameters, "domain")
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not list domains",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	if !call.IsSuccess() {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not list domains",
+			Detail:   fmt.Sprintf("API response not status code 1000. Got response: %s", call.ErrorMessage()),
+		})
+		return diags
+	}
+
+	names := make([]string, 0, len(domains))
+	result := make([]map[string]interface{}, 0, len(domains))
+	for _, domain := range domains {
+		domaint, ok := domain.(map[string]any)
+		if !ok {
+			continue
+		}
+		name, _ := domaint["domain"].(string)
+		// the domain parameter is a search pattern, make sure the TLD matches exactly
+		if tld != "" && !strings.HasSuffix(strings.ToLower(name), "."+tld) {
+			continue
+		}
+
+		item := map[string]interface{}{
+			"name": name,
+		}
+		if val, ok := domaint["status"].(string); ok {
+			item["status"] = val
+		}
+		if val, ok := domaint["exDate"].(string); ok {
+			item["expiration_date"] = val
+		}
+		names = append(names, name)
+		result = append(result, item)
+	}
+
+	d.Set("names", names)
+	if err := d.Set("domains", result); err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not set domains",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	d.SetId(fmt.Sprintf("domains:%s:%s", tld, status))
+
+	return diags
+}
@@ -0,0 +1,100 @@
+package datasource
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/inwx/terraform-provider-inwx/inwx/internal/api"
+)
+
+func GlueRecordDataSource() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceGlueRecordRead,
+		Schema: map[string]*schema.Schema{
+			"hostname": {
+				Description: "Name of host",
+				Type:        schema.TypeString,
+				Required:    true,
+			},
+			"ro_id": {
+				Description: "Id (Repository Object Identifier) of the host",
+				Type:        schema.TypeInt,
+				Computed:    true,
+			},
+			"ip": {
+				Description: "Ip address(es)",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"status": {
+				Description: "Status of the host",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func dataSourceGlueRecordRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	client := m.(*api.Client)
+
+	hostname := d.Get("hostname").(string)
+
+	call, err := client.Call(ctx, "host.info", map[string]interface{}{
+		"hostname": hostname,
+	})
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not get glue record info",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	if call.Code() != api.COMMAND_SUCCESSFUL {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not get glue record info",
+			Detail:   fmt.Sprintf("API response not status code 1000. Got response: %s", call.ErrorMessage()),
+		})
+		return diags
+	}
+
+	resData, _ := call["resData"].(map[string]any)
+
+	// the host is either returned directly or as the only entry of a record list
+	host := resData
+	if records, ok := resData["record"].([]any); ok && len(records) > 0 {
+		host, _ = records[0].(map[string]any)
+	}
+	roId, ok := host["roId"].(float64)
+	if !ok {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not find glue record",
+			Detail:   fmt.Sprintf("No host '%s' found. Got response: %s", hostname, call.ApiError()),
+		})
+		return diags
+	}
+
+	d.Set("ro_id", int(roId))
+	switch ip := host["ip"].(type) {
+	case []any:
+		d.Set("ip", ip)
+	case string:
+		d.Set("ip", []string{ip})
+	}
+	if status, ok := host["status"].(string); ok {
+		d.Set("status", status)
+	}
+
+	d.SetId(fmt.Sprintf("%s:%d", hostname, int(roId)))
+
+	return diags
+}
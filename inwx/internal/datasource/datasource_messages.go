@@ -0,0 +1,136 @@
+package datasource
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/inwx/terraform-provider-inwx/inwx/internal/api"
+)
+
+func MessagesDataSource() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceMessagesRead,
+		Schema: map[string]*schema.Schema{
+			"pending_count": {
+				Description: "Number of pending messages",
+				Type:        schema.TypeInt,
+				Computed:    true,
+			},
+			"oldest_message": {
+				Description: "Oldest pending message, empty if there are no messages. The poll returns only the oldest message and other messages are only returned after it was acknowledged",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Description: "Id of the message",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"type": {
+							Description: "Type of the message, e.g. a transfer request",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"domain": {
+							Description: "Domain the message refers to",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"text": {
+							Description: "Text of the message",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"date": {
+							Description: "Date of the message",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceMessagesRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	client := m.(*api.Client)
+
+	// message.poll does not acknowledge messages, so reading them does not remove them from the queue. As it returns the
+	// oldest message until it is acknowledged, the other pending messages cannot be read.
+	call, err := client.CallNoParams(ctx, "message.poll")
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not poll messages",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	// 1300 means that there are no messages
+	if call.Code() != api.COMMAND_SUCCESSFUL && call.Code() != 1300 {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not poll messages",
+			Detail:   fmt.Sprintf("API response not status code 1000. Got response: %s", call.ErrorMessage()),
+		})
+		return diags
+	}
+
+	resData, _ := call["resData"].(map[string]any)
+
+	var message map[string]any
+	switch msg := resData["msg"].(type) {
+	case []any:
+		if len(msg) > 0 {
+			message, _ = msg[0].(map[string]any)
+		}
+	case map[string]any:
+		message = msg
+	}
+
+	result := make([]map[string]interface{}, 0, 1)
+	if message != nil {
+		item := map[string]interface{}{}
+		if val, ok := message["id"]; ok {
+			item["id"] = fmt.Sprint(val)
+		}
+		for attribute, fields := range map[string][]string{
+			"type":   {"type"},
+			"domain": {"domain", "object"},
+			"text":   {"msg", "message"},
+			"date":   {"date", "qDate"},
+		} {
+			for _, field := range fields {
+				if val, ok := message[field].(string); ok {
+					item[attribute] = val
+					break
+				}
+			}
+		}
+		result = append(result, item)
+	}
+
+	count, ok := resData["count"].(float64)
+	if !ok {
+		count = float64(len(result))
+	}
+	d.Set("pending_count", int(count))
+
+	if err := d.Set("oldest_message", result); err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not set oldest message",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	d.SetId("messages")
+
+	return diags
+}
@@ -0,0 +1,82 @@
+package datasource
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/inwx/terraform-provider-inwx/inwx/internal/api/apitest"
+)
+
+func TestDataSourceMessagesRead(t *testing.T) {
+	tests := []struct {
+		name     string
+		response map[string]interface{}
+		want     map[string]interface{}
+		count    int
+	}{
+		{
+			name: "single message",
+			response: apitest.Success(map[string]interface{}{
+				"count": 3,
+				"msg":   map[string]interface{}{"id": 7, "type": "TRANSFER_IN", "object": "example.com", "msg": "Transfer request", "qDate": "2024-01-01"},
+			}),
+			want:  map[string]interface{}{"id": "7", "type": "TRANSFER_IN", "domain": "example.com", "text": "Transfer request", "date": "2024-01-01"},
+			count: 3,
+		},
+		{
+			name: "list of messages",
+			response: apitest.Success(map[string]interface{}{
+				"count": 2,
+				"msg": []interface{}{
+					map[string]interface{}{"id": 7, "type": "TRANSFER_IN", "domain": "example.com"},
+					map[string]interface{}{"id": 8, "type": "EXPIRATION", "domain": "example.net"},
+				},
+			}),
+			want:  map[string]interface{}{"id": "7", "type": "TRANSFER_IN", "domain": "example.com", "text": "", "date": ""},
+			count: 2,
+		},
+		{
+			name:     "no messages",
+			response: map[string]interface{}{"code": 1300, "msg": "Command completed successfully; no messages"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stub := apitest.NewStub()
+			stub.Respond("message.poll", test.response)
+
+			d := MessagesDataSource().Data(nil)
+			diags := dataSourceMessagesRead(context.Background(), d, stub.Client(t))
+			for _, diagnostic := range diags {
+				if diagnostic.Severity == diag.Error {
+					t.Fatalf("unexpected error: %s: %s", diagnostic.Summary, diagnostic.Detail)
+				}
+			}
+
+			if calls := stub.Calls("message.ack"); len(calls) != 0 {
+				t.Errorf("expected no message to be acknowledged, got %v", calls)
+			}
+			if got := d.Get("pending_count"); got != test.count {
+				t.Errorf("expected %d pending messages, got %v", test.count, got)
+			}
+
+			messages := d.Get("oldest_message").([]interface{})
+			if test.want == nil {
+				if len(messages) != 0 {
+					t.Errorf("expected no message, got %v", messages)
+				}
+				return
+			}
+			if len(messages) != 1 {
+				t.Fatalf("expected the oldest message, got %v", messages)
+			}
+			for key, want := range test.want {
+				if got := messages[0].(map[string]interface{})[key]; got != want {
+					t.Errorf("expected %s %q, got %q", key, want, got)
+				}
+			}
+		})
+	}
+}
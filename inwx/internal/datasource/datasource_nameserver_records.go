@@ -0,0 +1,145 @@
+package datasource
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/inwx/terraform-provider-inwx/inwx/internal/api"
+)
+
+func NameserverRecordsDataSource() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceNameserverRecordsRead,
+		Schema: map[string]*schema.Schema{
+			"domain": {
+				Description: "Domain name of the zone",
+				Type:        schema.TypeString,
+				Required:    true,
+			},
+			"type": {
+				Description: "Only return records of this type",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"records": {
+				Description: "Records of the zone",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Description: "Id of the record",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"name": {
+							Description: "Name of the record",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"type": {
+							Description: "Type of the record",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"content": {
+							Description: "Content of the record",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"ttl": {
+							Description: "TTL (time to live) of the record",
+							Type:        schema.TypeInt,
+							Computed:    true,
+						},
+						"prio": {
+							Description: "Priority of the record",
+							Type:        schema.TypeInt,
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceNameserverRecordsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	client := m.(*api.Client)
+
+	domain := d.Get("domain").(string)
+
+	call, err := client.NameserverInfo(ctx, domain)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not get nameserver info",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	if call.Code() != api.COMMAND_SUCCESSFUL {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not get nameserver info",
+			Detail:   fmt.Sprintf("API response not status code 1000. Got response: %s", call.ErrorMessage()),
+		})
+		return diags
+	}
+
+	var records []any
+	if resData, ok := call["resData"].(map[string]any); ok {
+		records, _ = resData["record"].([]any)
+	}
+
+	filterType, filtered := d.GetOk("type")
+
+	result := make([]map[string]interface{}, 0, len(records))
+	for _, record := range records {
+		recordt, ok := record.(map[string]any)
+		if !ok {
+			continue
+		}
+		if filtered && recordt["type"] != filterType {
+			continue
+		}
+
+		item := map[string]interface{}{}
+		if val, ok := recordt["id"].(float64); ok {
+			item["id"] = strconv.Itoa(int(val))
+		}
+		if val, ok := recordt["name"].(string); ok {
+			item["name"] = val
+		}
+		if val, ok := recordt["type"].(string); ok {
+			item["type"] = val
+		}
+		if val, ok := recordt["content"].(string); ok {
+			item["content"] = val
+		}
+		if val, ok := recordt["ttl"].(float64); ok {
+			item["ttl"] = int(val)
+		}
+		if val, ok := recordt["prio"].(float64); ok {
+			item["prio"] = int(val)
+		}
+		result = append(result, item)
+	}
+
+	if err := d.Set("records", result); err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not set records",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	d.SetId(domain)
+
+	return diags
+}
@@ -0,0 +1,111 @@
+package datasource
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/inwx/terraform-provider-inwx/inwx/internal/api"
+)
+
+func TldDataSource() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceTldRead,
+		Schema: map[string]*schema.Schema{
+			"tld": {
+				Description: "Top level domain without leading dot, e.g. com or co.uk",
+				Type:        schema.TypeString,
+				Required:    true,
+			},
+			"register_price": {
+				Description: "Price of a registration",
+				Type:        schema.TypeFloat,
+				Computed:    true,
+			},
+			"renew_price": {
+				Description: "Price of a renewal",
+				Type:        schema.TypeFloat,
+				Computed:    true,
+			},
+			"transfer_price": {
+				Description: "Price of a transfer",
+				Type:        schema.TypeFloat,
+				Computed:    true,
+			},
+			"currency": {
+				Description: "Currency of the prices",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"periods": {
+				Description: "Registration periods supported by the TLD, e.g. 1Y",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"period_min": {
+				Description: "Shortest supported registration period",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"period_max": {
+				Description: "Longest supported registration period",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func dataSourceTldRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	client := m.(*api.Client)
+
+	tld := strings.ToLower(strings.TrimPrefix(d.Get("tld").(string), "."))
+
+	price, call, err := client.TldPrice(ctx, tld)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not get domain prices",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	if price == nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not get domain prices",
+			Detail:   fmt.Sprintf("API response not status code 1000. Got response: %s", call.ErrorMessage()),
+		})
+		return diags
+	}
+
+	if val, ok := price["createPrice"].(float64); ok {
+		d.Set("register_price", val)
+	}
+	if val, ok := price["renewalPrice"].(float64); ok {
+		d.Set("renew_price", val)
+	}
+	if val, ok := price["transferPrice"].(float64); ok {
+		d.Set("transfer_price", val)
+	}
+	if val, ok := price["currency"].(string); ok {
+		d.Set("currency", val)
+	}
+
+	periods := api.TldPeriods(price)
+	d.Set("periods", periods)
+	if len(periods) > 0 {
+		d.Set("period_min", periods[0])
+		d.Set("period_max", periods[len(periods)-1])
+	}
+
+	d.SetId(tld)
+
+	return diags
+}
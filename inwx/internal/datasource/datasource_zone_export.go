@@ -0,0 +1,90 @@
+package datasource
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/inwx/terraform-provider-inwx/inwx/internal/api"
+	"github.com/inwx/terraform-provider-inwx/inwx/internal/zonefile"
+)
+
+func ZoneExportDataSource() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceZoneExportRead,
+		Schema: map[string]*schema.Schema{
+			"domain": {
+				Description: "Domain name of the zone",
+				Type:        schema.TypeString,
+				Required:    true,
+			},
+			"bind_zonefile": {
+				Description: "Records of the zone in BIND (RFC 1035 master file) format",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func dataSourceZoneExportRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	client := m.(*api.Client)
+
+	domain := strings.TrimSuffix(d.Get("domain").(string), ".")
+
+	call, err := client.NameserverInfo(ctx, domain)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not get nameserver info",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	if call.Code() != api.COMMAND_SUCCESSFUL {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not get nameserver info",
+			Detail:   fmt.Sprintf("API response not status code 1000. Got response: %s", call.ErrorMessage()),
+		})
+		return diags
+	}
+
+	var records []any
+	if resData, ok := call["resData"].(map[string]any); ok {
+		records, _ = resData["record"].([]any)
+	}
+
+	result := make([]zonefile.Record, 0, len(records))
+	for _, record := range records {
+		recordt, ok := record.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		item := zonefile.Record{}
+		item.Name, _ = recordt["name"].(string)
+		item.Type, _ = recordt["type"].(string)
+		item.Content, _ = recordt["content"].(string)
+		if val, ok := recordt["ttl"].(float64); ok {
+			item.TTL = int(val)
+		}
+		if val, ok := recordt["prio"].(float64); ok {
+			item.Prio = int(val)
+		}
+		if item.Name == "" {
+			item.Name = domain
+		}
+		result = append(result, item)
+	}
+
+	// every record is written with its own TTL, $TTL is the default TTL of new records of the provider
+	d.Set("bind_zonefile", zonefile.Format(domain, client.DefaultTTL, result))
+
+	d.SetId(domain)
+
+	return diags
+}
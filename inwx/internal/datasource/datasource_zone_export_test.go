@@ -0,0 +1,38 @@
+package datasource
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/inwx/terraform-provider-inwx/inwx/internal/api/apitest"
+)
+
+func TestDataSourceZoneExportDefaultTTL(t *testing.T) {
+	stub := apitest.NewStub()
+	stub.Respond("nameserver.info", apitest.Success(map[string]interface{}{
+		"record": []interface{}{
+			map[string]interface{}{"id": 18, "name": "www.example.com", "type": "A", "content": "192.0.2.1", "ttl": 300},
+		},
+	}))
+	client := stub.Client(t)
+	client.DefaultTTL = 600
+
+	d := ZoneExportDataSource().Data(nil)
+	d.Set("domain", "example.com")
+	diags := dataSourceZoneExportRead(context.Background(), d, client)
+	for _, diagnostic := range diags {
+		if diagnostic.Severity == diag.Error {
+			t.Fatalf("unexpected error: %s: %s", diagnostic.Summary, diagnostic.Detail)
+		}
+	}
+
+	zone := d.Get("bind_zonefile").(string)
+	if !strings.HasPrefix(zone, "$ORIGIN example.com.\n$TTL 600\n") {
+		t.Errorf("expected the default TTL of the provider as $TTL, got:\n%s", zone)
+	}
+	if !strings.Contains(zone, "www.example.com.\t300\tIN\tA\t192.0.2.1\n") {
+		t.Errorf("expected the record with its own TTL, got:\n%s", zone)
+	}
+}
@@ -0,0 +1,92 @@
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	ctyjson "github.com/hashicorp/go-cty/cty/json"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// stateData returns the resource data of an existing resource with id and the attributes of state, as passed to
+// Read and Delete.
+func stateData(t *testing.T, r *schema.Resource, id string, state map[string]interface{}) *schema.ResourceData {
+	t.Helper()
+
+	d := r.Data(nil)
+	for key, value := range state {
+		if err := d.Set(key, value); err != nil {
+			t.Fatalf("could not set %s: %v", key, err)
+		}
+	}
+	d.SetId(id)
+
+	return d
+}
+
+// unknownValue is how the sdk represents values in a configuration which are unknown while planning, e.g. the id of
+// a resource created in the same apply.
+const unknownValue = "74D93920-ED26-11E3-AC10-0800200C9A66"
+
+// diffResource plans config for a resource with id and state, or for a new resource if id is empty. The
+// CustomizeDiff of the resource is run with meta.
+func diffResource(t *testing.T, r *schema.Resource, id string, state map[string]interface{}, config map[string]interface{}, meta interface{}) (*terraform.InstanceState, *terraform.InstanceDiff, error) {
+	t.Helper()
+
+	var instanceState *terraform.InstanceState
+	if id != "" {
+		instanceState = stateData(t, r, id, state).State()
+	}
+
+	diff, err := r.Diff(context.Background(), instanceState, terraform.NewResourceConfigRaw(config), meta)
+	if err != nil || diff == nil {
+		return instanceState, diff, err
+	}
+
+	// the raw config is sent by terraform core and read with GetRawConfig, e.g. to tell unset from false
+	rawConfig, err := json.Marshal(config)
+	if err != nil {
+		t.Fatalf("could not marshal config: %v", err)
+	}
+	diff.RawConfig, err = ctyjson.Unmarshal(rawConfig, schema.InternalMap(r.Schema).CoreConfigSchema().ImpliedType())
+	if err != nil {
+		t.Fatalf("could not convert config: %v", err)
+	}
+
+	return instanceState, diff, nil
+}
+
+// planData returns the resource data of applying config to a resource with id and state, as passed to Create (for
+// an empty id) or Update.
+func planData(t *testing.T, r *schema.Resource, id string, state map[string]interface{}, config map[string]interface{}, meta interface{}) *schema.ResourceData {
+	t.Helper()
+
+	instanceState, diff, err := diffResource(t, r, id, state, config, meta)
+	if err != nil {
+		t.Fatalf("could not plan: %v", err)
+	}
+	if diff == nil {
+		diff = &terraform.InstanceDiff{}
+	}
+
+	d, err := schema.InternalMap(r.Schema).Data(instanceState, diff)
+	if err != nil {
+		t.Fatalf("could not create resource data: %v", err)
+	}
+
+	return d
+}
+
+// requireNoErrors fails the test if diags contain an error.
+func requireNoErrors(t *testing.T, diags diag.Diagnostics) {
+	t.Helper()
+
+	for _, d := range diags {
+		if d.Severity == diag.Error {
+			t.Fatalf("unexpected error: %s: %s", d.Summary, d.Detail)
+		}
+	}
+}
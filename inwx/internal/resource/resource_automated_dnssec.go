@@ -20,6 +20,19 @@ func AutomatedDNSSECResource() *schema.Resource {
 				Required:    true,
 				ForceNew:    true,
 			},
+			"dnssec_status": {
+				Description: "DNSSEC status of the domain, AUTO while automated DNSSEC is enabled",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"ds_records": {
+				Description: "DS records of the active keys of the domain: <key_tag> <algorithm> <digest_type> <digest>",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
 		},
 	}
 }
@@ -41,24 +54,77 @@ func resourceAutomatedDNSSECRead(ctx context.Context, d *schema.ResourceData, m
 		})
 		return diags
 	}
-	if call.Code() != api.COMMAND_SUCCESSFUL && call.Code() != api.COMMAND_SUCCESSFUL_PENDING {
+	if !call.IsSuccess() {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
 			Summary:  "Could not read DNSSEC info",
-			Detail:   fmt.Sprintf("API response not status code 1000 or 1001. Got response: %s", call.ApiError()),
+			Detail:   fmt.Sprintf("API response not status code 1000 or 1001. Got response: %s", call.ErrorMessage()),
 		})
 		return diags
 	}
 
-	records := call["resData"].(map[string]any)["record"].([]any)
+	resData, _ := call["resData"].(map[string]any)
+	records, _ := resData["record"].([]any)
 
 	for _, record := range records {
-		recordt := record.(map[string]any)
+		recordt, ok := record.(map[string]any)
+		if !ok {
+			continue
+		}
+		domain, _ := recordt["domain"].(string)
+		if domain != d.Get("domain").(string) {
+			continue
+		}
+
+		dnssecStatus, _ := recordt["dnssecStatus"].(string)
+		d.Set("dnssec_status", dnssecStatus)
+		if dnssecStatus == "AUTO" {
+			d.SetId(domain)
+		}
+	}
+
+	return resourceAutomatedDNSSECReadDSRecords(ctx, d, client)
+}
+
+// resourceAutomatedDNSSECReadDSRecords reads the DS records of the keys which INWX created for the domain. For TLDs
+// with CDS support, they show up once the registry imported the keys.
+func resourceAutomatedDNSSECReadDSRecords(ctx context.Context, d *schema.ResourceData, client *api.Client) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	parameters := map[string]interface{}{
+		"domainName": d.Get("domain").(string),
+		"active":     1,
+	}
+
+	call, err := client.Call(ctx, "dnssec.listkeys", parameters)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not get DNSSEC keys",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	if call.Code() != api.COMMAND_SUCCESSFUL {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not get DNSSEC keys",
+			Detail:   fmt.Sprintf("API response not status code 1000. Got response: %s", call.ErrorMessage()),
+		})
+		return diags
+	}
+
+	keys, _ := call["resData"].([]interface{})
 
-		if recordt["domain"].(string) == d.Get("domain").(string) && recordt["dnssecStatus"].(string) == "AUTO" {
-			d.SetId(recordt["domain"].(string))
+	dsRecords := make([]string, 0, len(keys))
+	for _, key := range keys {
+		keyt, ok := key.(map[string]interface{})
+		if !ok {
+			continue
 		}
+		dsRecords = append(dsRecords, fmt.Sprintf("%v %v %v %v", keyt["keyTag"], keyt["algorithmId"], keyt["digestTypeId"], keyt["digest"]))
 	}
+	d.Set("ds_records", dsRecords)
 
 	return diags
 }
@@ -80,11 +146,11 @@ func resourceAutomatedDNSSECCreate(ctx context.Context, d *schema.ResourceData,
 		})
 		return diags
 	}
-	if call.Code() != api.COMMAND_SUCCESSFUL && call.Code() != api.COMMAND_SUCCESSFUL_PENDING {
+	if !call.IsSuccess() {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
 			Summary:  "Could not enable automated DNSSEC",
-			Detail:   fmt.Sprintf("API response not status code 1000 or 1001. Got response: %s", call.ApiError()),
+			Detail:   fmt.Sprintf("API response not status code 1000 or 1001. Got response: %s", call.ErrorMessage()),
 		})
 		return diags
 	}
@@ -111,11 +177,11 @@ func resourceAutomatedDNSSECDelete(ctx context.Context, d *schema.ResourceData,
 		})
 		return diags
 	}
-	if call.Code() != api.COMMAND_SUCCESSFUL && call.Code() != api.COMMAND_SUCCESSFUL_PENDING {
+	if !call.IsSuccess() {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
 			Summary:  "Could not disable automated DNSSEC",
-			Detail:   fmt.Sprintf("API response not status code 1000 pr 1001. Got response: %s", call.ApiError()),
+			Detail:   fmt.Sprintf("API response not status code 1000 pr 1001. Got response: %s", call.ErrorMessage()),
 		})
 		return diags
 	}
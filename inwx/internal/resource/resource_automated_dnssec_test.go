@@ -0,0 +1,49 @@
+package resource
+
+import (
+	"context"
+	"testing"
+
+	"github.com/inwx/terraform-provider-inwx/inwx/internal/api/apitest"
+)
+
+func TestResourceAutomatedDNSSECRead(t *testing.T) {
+	stub := apitest.NewStub()
+	stub.Respond("dnssec.info", apitest.Success(map[string]interface{}{
+		"record": []interface{}{
+			"not a record",
+			map[string]interface{}{"dnssecStatus": "AUTO"},
+			map[string]interface{}{"domain": "example.net", "dnssecStatus": "MANUAL"},
+			map[string]interface{}{"domain": "example.com", "dnssecStatus": "AUTO"},
+		},
+	}))
+	stub.Respond("dnssec.listkeys", map[string]interface{}{
+		"code":    1000,
+		"resData": []interface{}{dnssecKey("42", "BBBB")},
+	})
+
+	d := stateData(t, AutomatedDNSSECResource(), "", map[string]interface{}{"domain": "example.com"})
+	requireNoErrors(t, resourceAutomatedDNSSECRead(context.Background(), d, stub.Client(t)))
+
+	if d.Id() != "example.com" || d.Get("dnssec_status") != "AUTO" {
+		t.Errorf("expected automated DNSSEC of example.com, got id %q, status %v", d.Id(), d.Get("dnssec_status"))
+	}
+	if ds := d.Get("ds_records").([]interface{}); len(ds) != 1 || ds[0] != "12345 13 2 BBBB" {
+		t.Errorf("unexpected ds_records %v", ds)
+	}
+}
+
+func TestResourceAutomatedDNSSECReadMalformedResponse(t *testing.T) {
+	stub := apitest.NewStub()
+	stub.Respond("dnssec.info", apitest.Success(map[string]interface{}{
+		"record": []interface{}{map[string]interface{}{"domain": "example.com", "dnssecStatus": 1}},
+	}))
+	stub.Respond("dnssec.listkeys", map[string]interface{}{"code": 1000})
+
+	d := stateData(t, AutomatedDNSSECResource(), "", map[string]interface{}{"domain": "example.com"})
+	requireNoErrors(t, resourceAutomatedDNSSECRead(context.Background(), d, stub.Client(t)))
+
+	if d.Id() != "" {
+		t.Errorf("expected no id for an unknown status, got %s", d.Id())
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/inwx/terraform-provider-inwx/inwx/internal/api"
 )
 
@@ -55,14 +56,20 @@ func DNSSECKeyResource() *schema.Resource {
 				Computed:    true,
 			},
 			"digest_type": {
-				Description: "Digest type",
-				Type:        schema.TypeInt,
-				Computed:    true,
+				Description:  "Digest type (1=SHA-1, 2=SHA-256, 4=SHA-384). If unset, the digest type is chosen by the api",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Computed:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.IntInSlice([]int{1, 2, 4}),
 			},
 			"flag": {
-				Description: "Key flag (256=ZSK, 257=KSK)",
-				Type:        schema.TypeInt,
-				Computed:    true,
+				Description:  "Key flag (256=ZSK, 257=KSK)",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ForceNew:     true,
+				Default:      257,
+				ValidateFunc: validation.IntInSlice([]int{256, 257}),
 			},
 			"key_tag": {
 				Description: "Key tag",
@@ -85,13 +92,17 @@ func resourceDNSSECKeyCreate(ctx context.Context, d *schema.ResourceData, m inte
 	parameters := map[string]interface{}{
 		"domainName": d.Get("domain").(string),
 		"dnskey": fmt.Sprintf(
-			"%s. IN DNSKEY 257 3 %d %s",
+			"%s. IN DNSKEY %d 3 %d %s",
 			d.Get("domain").(string),
+			d.Get("flag").(int),
 			d.Get("algorithm").(int),
 			d.Get("public_key").(string),
 		),
 		"calculateDigest": true,
 	}
+	if digestType, ok := d.GetOk("digest_type"); ok {
+		parameters["digestType"] = digestType
+	}
 
 	call, err := client.Call(ctx, "dnssec.adddnskey", parameters)
 	if err != nil {
@@ -102,11 +113,11 @@ func resourceDNSSECKeyCreate(ctx context.Context, d *schema.ResourceData, m inte
 		})
 		return diags
 	}
-	if call.Code() != api.COMMAND_SUCCESSFUL && call.Code() != api.COMMAND_SUCCESSFUL_PENDING {
+	if !call.IsSuccess() {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
 			Summary:  "Could not add DNSKEY",
-			Detail:   fmt.Sprintf("API response not status code 1000 or 1001. Got response: %s", call.ApiError()),
+			Detail:   fmt.Sprintf("API response not status code 1000 or 1001. Got response: %s", call.ErrorMessage()),
 		})
 		return diags
 	}
@@ -125,6 +136,10 @@ func resourceDNSSECKeyCreate(ctx context.Context, d *schema.ResourceData, m inte
 
 	d.Set("digest", parts[3])
 
+	if client.Testing {
+		return diags
+	}
+
 	resourceDNSSECKeyRead(ctx, d, m)
 
 	return diags
@@ -153,15 +168,48 @@ func resourceDNSSECKeyRead(ctx context.Context, d *schema.ResourceData, m interf
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
 			Summary:  "Could not get DNSSEC keys",
-			Detail:   fmt.Sprintf("API response not status code 1000. Got response: %s", call.ApiError()),
+			Detail:   fmt.Sprintf("API response not status code 1000. Got response: %s", call.ErrorMessage()),
 		})
 		return diags
 	}
 
-	resData := call["resData"].([]interface{})
-	key := resData[0].(map[string]interface{})
+	resData, _ := call["resData"].([]interface{})
 
-	d.SetId(key["id"].(string))
+	// a domain can have several keys, e.g. during a key rollover, so match the key of this resource
+	var key map[string]interface{}
+	for _, item := range resData {
+		itemt, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if id, ok := dnssecKeyId(itemt); ok && d.Id() != "" && id == d.Id() {
+			key = itemt
+			break
+		}
+		if digest, _ := itemt["digest"].(string); key == nil && strings.EqualFold(digest, d.Get("digest").(string)) {
+			key = itemt
+		}
+	}
+	if key == nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not find DNSSEC key",
+			Detail: fmt.Sprintf("No active DNSSEC key with id '%s' or digest '%s' found for domain %s. Got response: %s",
+				d.Id(), d.Get("digest").(string), d.Get("domain").(string), call.ApiError()),
+		})
+		return diags
+	}
+
+	id, ok := dnssecKeyId(key)
+	if !ok {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not find DNSSEC key",
+			Detail:   fmt.Sprintf("API response contains no id of the DNSSEC key. Got response: %s", call.ApiError()),
+		})
+		return diags
+	}
+	d.SetId(id)
 	d.Set("domain", key["ownerName"].(string))
 	d.Set("public_key", key["publicKey"].(string))
 	d.Set("digest", key["digest"].(string))
@@ -227,14 +275,26 @@ func resourceDNSSECKeyDelete(ctx context.Context, d *schema.ResourceData, m inte
 		})
 		return diags
 	}
-	if call.Code() != api.COMMAND_SUCCESSFUL && call.Code() != api.COMMAND_SUCCESSFUL_PENDING {
+	if !call.IsSuccess() {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
 			Summary:  "Could not delete DNSKEY",
-			Detail:   fmt.Sprintf("API response not status code 1000 pr 1001. Got response: %s", call.ApiError()),
+			Detail:   fmt.Sprintf("API response not status code 1000 pr 1001. Got response: %s", call.ErrorMessage()),
 		})
 		return diags
 	}
 
 	return diags
 }
+
+// dnssecKeyId returns the id of a key of dnssec.listkeys, which is returned as string or as number.
+func dnssecKeyId(key map[string]interface{}) (string, bool) {
+	switch id := key["id"].(type) {
+	case string:
+		return id, id != ""
+	case float64:
+		return strconv.Itoa(int(id)), true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,65 @@
+package resource
+
+import (
+	"context"
+	"testing"
+
+	"github.com/inwx/terraform-provider-inwx/inwx/internal/api/apitest"
+)
+
+// dnssecKey returns a key of example.com as returned by dnssec.listkeys with the given id.
+func dnssecKey(id interface{}, digest string) map[string]interface{} {
+	return map[string]interface{}{
+		"id":           id,
+		"ownerName":    "example.com",
+		"publicKey":    "mdsswUyr3DPW132mOi8V9xESWE8jTo0dxCjjnopKl+GqJxpVXckHAeF+KkxLbxILfDLUT0rAK9iUzy1L53eKGQ==",
+		"digest":       digest,
+		"status":       "PUBLISHED",
+		"algorithmId":  "13",
+		"digestTypeId": "2",
+		"flagId":       "257",
+		"keyTag":       "12345",
+	}
+}
+
+func TestResourceDNSSECKeyReadIds(t *testing.T) {
+	for _, id := range []interface{}{"42", 42} {
+		stub := apitest.NewStub()
+		stub.Respond("dnssec.listkeys", map[string]interface{}{
+			"code":    1000,
+			"resData": []interface{}{dnssecKey(41, "AAAA"), dnssecKey(id, "BBBB")},
+		})
+
+		d := stateData(t, DNSSECKeyResource(), "42", map[string]interface{}{"domain": "example.com", "digest": "CCCC"})
+		requireNoErrors(t, resourceDNSSECKeyRead(context.Background(), d, stub.Client(t)))
+
+		if d.Id() != "42" || d.Get("digest") != "BBBB" || d.Get("key_tag") != 12345 {
+			t.Errorf("expected key 42 for id %#v, got id %s, digest %v", id, d.Id(), d.Get("digest"))
+		}
+	}
+}
+
+func TestResourceDNSSECKeyReadWithoutId(t *testing.T) {
+	stub := apitest.NewStub()
+	stub.Respond("dnssec.listkeys", map[string]interface{}{
+		"code":    1000,
+		"resData": []interface{}{dnssecKey(nil, "BBBB")},
+	})
+
+	d := stateData(t, DNSSECKeyResource(), "", map[string]interface{}{"domain": "example.com", "digest": "bbbb"})
+	if diags := resourceDNSSECKeyRead(context.Background(), d, stub.Client(t)); !diags.HasError() {
+		t.Error("expected error for a key without id")
+	}
+}
+
+func TestResourceDNSSECKeyDelete(t *testing.T) {
+	stub := apitest.NewStub()
+	stub.Respond("dnssec.deletednskey", apitest.Success(nil))
+
+	d := stateData(t, DNSSECKeyResource(), "42", map[string]interface{}{"domain": "example.com", "digest": "BBBB"})
+	requireNoErrors(t, resourceDNSSECKeyDelete(context.Background(), d, stub.Client(t)))
+
+	if got, ok := stub.LastCall("dnssec.deletednskey")["key"].(string); !ok || got != "42" {
+		t.Errorf("expected the plain key id 42, got %#v", stub.LastCall("dnssec.deletednskey")["key"])
+	}
+}
@@ -6,10 +6,15 @@ import (
 	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/inwx/terraform-provider-inwx/inwx/internal/api"
+	"regexp"
+	"strconv"
 	"strings"
 )
 
+var domainPeriodRegexp = regexp.MustCompile(`^[0-9]+[YM]?$`)
+
 func DomainResource() *schema.Resource {
 	validRenewalModes := []string{
 		"AUTORENEW",
@@ -22,9 +27,11 @@ func DomainResource() *schema.Resource {
 		ReadContext:   resourceDomainRead,
 		UpdateContext: resourceDomainUpdate,
 		DeleteContext: resourceDomainDelete,
+		CustomizeDiff: resourceDomainCustomizeDiff,
 		Importer: &schema.ResourceImporter{
 			StateContext: func(ctx context.Context, data *schema.ResourceData, i interface{}) ([]*schema.ResourceData, error) {
 				data.Set("name", data.Id())
+				data.Set("manage_nameservers", true)
 				return schema.ImportStatePassthroughContext(ctx, data, i)
 			},
 		},
@@ -41,18 +48,45 @@ func DomainResource() *schema.Resource {
 					MinItems: 1,
 				},
 				Optional:    true,
-				Description: "Set of nameservers of the domain",
+				Computed:    true,
+				Description: "Set of nameservers of the domain. If unset, the nameservers of the domain are not managed",
+			},
+			"nameserver_set_id": {
+				Type:          schema.TypeInt,
+				Optional:      true,
+				Description:   "Id of a nameserver set to use instead of nameservers",
+				ConflictsWith: []string{"nameservers"},
+			},
+			"manage_nameservers": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Whether nameservers and nameserver_set_id are read back and updated. If false, they are only sent on registration, for domains whose DNS is managed elsewhere",
 			},
 			"period": {
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: "Registration period of the domain",
+				Type:         schema.TypeString,
+				Required:     true,
+				Description:  "Registration period of the domain, e.g. 1Y or 12M. A bare number is interpreted in period_unit",
+				ValidateFunc: validation.StringMatch(domainPeriodRegexp, "must be a number, optionally followed by Y (years) or M (months), e.g. 1Y"),
+			},
+			"period_unit": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "Y",
+				Description:  "Unit of a period given as bare number. One of: Y (years), M (months)",
+				ValidateFunc: validation.StringInSlice([]string{"Y", "M"}, false),
+			},
+			"validate_period": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Whether to check the period against the periods supported by the TLD when planning. Requires an additional API call",
 			},
 			"renewal_mode": {
 				Type:        schema.TypeString,
 				Optional:    true,
-				Default:     "AUTORENEW",
-				Description: "Renewal mode of the domain. One of: " + strings.Join(validRenewalModes, ", "),
+				Computed:    true,
+				Description: "Renewal mode of the domain. Defaults to AUTORENEW on create. One of: " + strings.Join(validRenewalModes, ", "),
 				ValidateDiagFunc: func(i interface{}, path cty.Path) diag.Diagnostics {
 					var diags diag.Diagnostics
 					for _, validRenewalMode := range validRenewalModes {
@@ -73,8 +107,14 @@ func DomainResource() *schema.Resource {
 			"transfer_lock": {
 				Type:        schema.TypeBool,
 				Optional:    true,
-				Default:     true,
-				Description: "Whether the domain transfer lock should be enabled",
+				Computed:    true,
+				Description: "Whether the domain transfer lock should be enabled. If unset, the default of the registry is used and the transfer lock is not managed",
+			},
+			"relock_after_update": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Whether to remove the transfer lock while changing nameservers or contacts and to enable it again afterwards, for registries rejecting these changes on locked domains",
 			},
 			"contacts": {
 				Type:        schema.TypeSet,
@@ -84,6 +124,32 @@ func DomainResource() *schema.Resource {
 				Elem:        contactsSchemaResource(),
 				Description: "Contacts of the domain",
 			},
+			"fetch_renewal_cost": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Whether to look up the renewal cost of the domain on every read",
+			},
+			"renewal_cost": {
+				Type:        schema.TypeFloat,
+				Computed:    true,
+				Description: "Renewal price of the domain TLD. Only set when fetch_renewal_cost is enabled",
+			},
+			"renewal_currency": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Currency of renewal_cost. Only set when fetch_renewal_cost is enabled",
+			},
+			"ro_id": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Id (Repository Object Identifier) of the domain",
+			},
+			"whois_protection_active": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether WHOIS protection is active for the domain, either via extra data or the registrant contact",
+			},
 			"extra_data": {
 				Type:        schema.TypeMap,
 				Optional:    true,
@@ -99,24 +165,28 @@ func contactsSchemaResource() *schema.Resource {
 	return &schema.Resource{
 		Schema: map[string]*schema.Schema{
 			"registrant": {
-				Type:        schema.TypeInt,
-				Required:    true,
-				Description: "Id of the registrant contact",
+				Type:         schema.TypeInt,
+				Required:     true,
+				Description:  "Id of the registrant contact",
+				ValidateFunc: validation.IntAtLeast(1),
 			},
 			"admin": {
-				Type:        schema.TypeInt,
-				Required:    true,
-				Description: "Id of the admin contact",
+				Type:         schema.TypeInt,
+				Required:     true,
+				Description:  "Id of the admin contact",
+				ValidateFunc: validation.IntAtLeast(1),
 			},
 			"tech": {
-				Type:        schema.TypeInt,
-				Required:    true,
-				Description: "Id of the tech contact",
+				Type:         schema.TypeInt,
+				Required:     true,
+				Description:  "Id of the tech contact",
+				ValidateFunc: validation.IntAtLeast(1),
 			},
 			"billing": {
-				Type:        schema.TypeInt,
-				Required:    true,
-				Description: "Id of the billing contact",
+				Type:         schema.TypeInt,
+				Required:     true,
+				Description:  "Id of the billing contact",
+				ValidateFunc: validation.IntAtLeast(1),
 			},
 		},
 	}
@@ -130,15 +200,25 @@ func resourceDomainCreate(ctx context.Context, d *schema.ResourceData, m interfa
 	contactIds := d.Get("contacts").(*schema.Set).List()[0].(map[string]interface{})
 
 	parameters := map[string]interface{}{
-		"domain":       d.Get("name").(string),
-		"ns":           d.Get("nameservers").(*schema.Set).List(),
-		"period":       d.Get("period").(string),
-		"registrant":   contactIds["registrant"],
-		"admin":        contactIds["admin"],
-		"tech":         contactIds["tech"],
-		"billing":      contactIds["billing"],
-		"transferLock": d.Get("transfer_lock").(bool),
-		"renewalMode":  d.Get("renewal_mode").(string),
+		"domain":      d.Get("name").(string),
+		"period":      expandDomainPeriod(d.Get("period").(string), d.Get("period_unit").(string)),
+		"registrant":  contactIds["registrant"],
+		"admin":       contactIds["admin"],
+		"tech":        contactIds["tech"],
+		"billing":     contactIds["billing"],
+		"renewalMode": "AUTORENEW",
+	}
+	// the transfer lock is only sent if configured, some TLDs without transfer lock reject the parameter
+	if !d.GetRawConfig().GetAttr("transfer_lock").IsNull() {
+		parameters["transferLock"] = d.Get("transfer_lock").(bool)
+	}
+	if renewalMode, ok := d.GetOk("renewal_mode"); ok {
+		parameters["renewalMode"] = renewalMode
+	}
+	if nameserverSetId, ok := d.GetOk("nameserver_set_id"); ok {
+		parameters["nsSetId"] = nameserverSetId
+	} else if nameservers, ok := d.GetOk("nameservers"); ok {
+		parameters["ns"] = nameservers.(*schema.Set).List()
 	}
 	if extraData, ok := d.GetOk("extra_data"); ok {
 		parameters["extData"] = extraData
@@ -153,11 +233,11 @@ func resourceDomainCreate(ctx context.Context, d *schema.ResourceData, m interfa
 		})
 		return diags
 	}
-	if call.Code() != api.COMMAND_SUCCESSFUL && call.Code() != api.COMMAND_SUCCESSFUL_PENDING {
+	if !call.IsSuccess() {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
 			Summary:  "Could not create domain",
-			Detail:   fmt.Sprintf("API response not status code 1000 or 1001. Got response: %s", call.ApiError()),
+			Detail:   fmt.Sprintf("API response not status code 1000 or 1001. Got response: %s", call.ErrorMessage()),
 		})
 		return diags
 	}
@@ -185,36 +265,119 @@ func resourceDomainRead(ctx context.Context, d *schema.ResourceData, meta interf
 		})
 		return diags
 	}
+	if call.Code() == 2303 { // Object does not exist
+		d.SetId("")
+		return diags
+	}
 	if call.Code() != api.COMMAND_SUCCESSFUL {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
 			Summary:  "Could not get domain info",
-			Detail:   fmt.Sprintf("API response not status code 1000. Got response: %s", call.ApiError()),
+			Detail:   fmt.Sprintf("API response not status code 1000. Got response: %s", call.ErrorMessage()),
 		})
 		return diags
 	}
 
 	resData := call["resData"].(map[string]interface{})
 	d.Set("name", resData["domain"])
-	d.Set("nameservers", resData["ns"])
-	d.Set("period", resData["period"])
-	d.Set("renewal_mode", resData["renewalMode"])
-	d.Set("transfer_lock", resData["transferLock"])
+	if roId, ok := resData["roId"].(float64); ok {
+		d.Set("ro_id", int(roId))
+	}
+	if domainManagesNameservers(d) {
+		nameservers, _ := resData["ns"].([]interface{})
+		d.Set("nameservers", flattenNameservers(d.Get("nameservers").(*schema.Set).List(), nameservers))
+		// the api also reports the set of domains whose nameservers are configured directly, so the set is only
+		// read back if it is used in the configuration
+		if _, ok := d.GetOk("nameserver_set_id"); ok {
+			nameserverSetId, _ := resData["nsSetId"].(float64)
+			d.Set("nameserver_set_id", int(nameserverSetId))
+		}
+	}
+	if period, ok := resData["period"].(string); ok {
+		d.Set("period", normalizePeriod(period, d.Get("period").(string), d.Get("period_unit").(string)))
+	}
+	if renewalMode, ok := resData["renewalMode"].(string); ok {
+		d.Set("renewal_mode", renewalMode)
+	}
+	// the api omits the transfer lock for TLDs which do not support it, these domains are never locked
+	if transferLock, ok := resData["transferLock"]; ok {
+		d.Set("transfer_lock", api.IsTruthy(transferLock))
+	} else {
+		d.Set("transfer_lock", false)
+	}
 
 	contacts := map[string]interface{}{}
-	contacts["registrant"] = int(resData["registrant"].(float64))
-	contacts["admin"] = int(resData["admin"].(float64))
-	contacts["tech"] = int(resData["tech"].(float64))
-	contacts["billing"] = int(resData["billing"].(float64))
+	for _, role := range []string{"registrant", "admin", "tech", "billing"} {
+		contactId, err := domainContactId(resData, role)
+		if err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  fmt.Sprintf("Could not read %s contact of domain", role),
+				Detail:   err.Error(),
+			})
+			return diags
+		}
+		contacts[role] = contactId
+	}
 
 	d.Set("contacts", schema.NewSet(schema.HashResource(contactsSchemaResource()), []interface{}{contacts}))
-	d.Set("extra_data", resData["extData"])
+	d.Set("extra_data", filterManagedExtraData(d.Get("extra_data").(map[string]interface{}), resData["extData"]))
+	d.Set("whois_protection_active", whoisProtectionActive(resData))
+
+	if d.Get("fetch_renewal_cost").(bool) {
+		price, priceDiags := getTldPrice(ctx, client, domainTld(d.Id()))
+		if priceDiags.HasError() {
+			return priceDiags
+		}
+		if renewalPrice, ok := price["renewalPrice"].(float64); ok {
+			d.Set("renewal_cost", renewalPrice)
+		}
+		if currency, ok := price["currency"].(string); ok {
+			d.Set("renewal_currency", currency)
+		}
+	} else {
+		d.Set("renewal_cost", nil)
+		d.Set("renewal_currency", nil)
+	}
 
 	return diags
 }
 
-func resourceDomainUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	var diags diag.Diagnostics
+// whoisProtectionActive derives the effective WHOIS protection from the WHOIS-PROTECTION extra data, which is
+// read even if it is not managed by terraform, and the protection flag of the registrant contact, if returned.
+func whoisProtectionActive(resData map[string]interface{}) bool {
+	if extData, ok := resData["extData"].(map[string]interface{}); ok && api.IsTruthy(extData["WHOIS-PROTECTION"]) {
+		return true
+	}
+
+	if contacts, ok := resData["contact"].(map[string]interface{}); ok {
+		if registrant, ok := contacts["registrant"].(map[string]interface{}); ok && api.IsTruthy(registrant["protection"]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// domainContactId returns the id of the contact with the given role from a domain.info response. The id is either
+// returned at the top level, or inside a contact object below "contact", both as number or string.
+func domainContactId(resData map[string]interface{}, role string) (int, error) {
+	rawContact, ok := resData[role]
+	if !ok {
+		contacts, _ := resData["contact"].(map[string]interface{})
+		if rawContact, ok = contacts[role]; !ok {
+			return 0, fmt.Errorf("no %s contact in response", role)
+		}
+	}
+
+	if contact, ok := rawContact.(map[string]interface{}); ok {
+		rawContact = contact["id"]
+	}
+
+	return coerceContactId(rawContact)
+}
+
+func resourceDomainUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) (diags diag.Diagnostics) {
 	client := meta.(*api.Client)
 
 	if d.HasChange("name") {
@@ -229,11 +392,35 @@ func resourceDomainUpdate(ctx context.Context, d *schema.ResourceData, meta inte
 		"domain": d.Get("name"),
 	}
 
-	if d.HasChange("nameservers") {
-		parameters["ns"] = d.Get("nameservers").(*schema.Set).List()
+	manageNameservers := domainManagesNameservers(d)
+
+	oldTransferLock, newTransferLock := d.GetChange("transfer_lock")
+	relock := d.Get("relock_after_update").(bool) &&
+		((manageNameservers && d.HasChanges("nameservers", "nameserver_set_id")) || d.HasChange("contacts")) &&
+		oldTransferLock.(bool) && newTransferLock.(bool)
+	if relock {
+		unlockDiags := setDomainTransferLock(ctx, client, d.Get("name").(string), false)
+		if unlockDiags.HasError() {
+			return unlockDiags
+		}
+		// the lock is enabled again in any case, even if the update fails
+		defer func() {
+			diags = append(diags, setDomainTransferLock(ctx, client, d.Get("name").(string), true)...)
+		}()
 	}
-	if d.HasChange("period") {
-		parameters["period"] = d.Get("period")
+
+	// without manage_nameservers the nameservers are managed elsewhere and changes are only kept in state
+	if manageNameservers {
+		if nameserverSetId, ok := d.GetOk("nameserver_set_id"); ok {
+			if d.HasChange("nameserver_set_id") {
+				parameters["nsSetId"] = nameserverSetId
+			}
+		} else if d.HasChange("nameservers") {
+			parameters["ns"] = d.Get("nameservers").(*schema.Set).List()
+		}
+	}
+	if d.HasChanges("period", "period_unit") {
+		parameters["period"] = expandDomainPeriod(d.Get("period").(string), d.Get("period_unit").(string))
 	}
 	if d.HasChange("renewal_mode") {
 		parameters["renewalMode"] = d.Get("renewal_mode")
@@ -242,14 +429,35 @@ func resourceDomainUpdate(ctx context.Context, d *schema.ResourceData, meta inte
 		parameters["transferLock"] = d.Get("transfer_lock")
 	}
 	if d.HasChange("contacts") {
-		contacts := d.Get("contacts").(*schema.Set).List()[0].(map[string]interface{})
-		parameters["registrant"] = contacts["registrant"]
-		parameters["admin"] = contacts["admin"]
-		parameters["tech"] = contacts["tech"]
-		parameters["billing"] = contacts["billing"]
+		oldContacts, newContacts := d.GetChange("contacts")
+		oldContactIds := oldContacts.(*schema.Set).List()[0].(map[string]interface{})
+		newContactIds := newContacts.(*schema.Set).List()[0].(map[string]interface{})
+
+		// a new registrant is an owner change, which the registry handles as a trade
+		if oldContactIds["registrant"] != newContactIds["registrant"] {
+			tradeDiags := resourceDomainTrade(ctx, client, d.Get("name").(string), newContactIds["registrant"])
+			if tradeDiags.HasError() {
+				return tradeDiags
+			}
+		}
+
+		for _, role := range []string{"admin", "tech", "billing"} {
+			if oldContactIds[role] != newContactIds[role] {
+				parameters[role] = newContactIds[role]
+			}
+		}
 	}
 	if d.HasChange("extra_data") {
-		parameters["extData"] = d.Get("extra_data")
+		extData, extDataDiags := mergeExtraData(ctx, client, d)
+		if extDataDiags.HasError() {
+			return extDataDiags
+		}
+		parameters["extData"] = extData
+	}
+
+	if len(parameters) == 1 {
+		// nothing besides the registrant changed
+		return diags
 	}
 
 	call, err := client.Call(ctx, "domain.update", parameters)
@@ -261,11 +469,128 @@ func resourceDomainUpdate(ctx context.Context, d *schema.ResourceData, meta inte
 		})
 		return diags
 	}
-	if call.Code() != api.COMMAND_SUCCESSFUL && call.Code() != api.COMMAND_SUCCESSFUL_PENDING {
+	if !call.IsSuccess() {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
 			Summary:  "Could not get domain info",
-			Detail:   fmt.Sprintf("API response not status code 1000 or 1001. Got response: %s", call.ApiError()),
+			Detail:   fmt.Sprintf("API response not status code 1000 or 1001. Got response: %s", call.ErrorMessage()),
+		})
+		return diags
+	}
+
+	return diags
+}
+
+// resourceDomainCustomizeDiff checks the period against period_unit and the periods of the TLD, so that an unsupported
+// period is reported at plan time instead of failing with an api error on apply.
+func resourceDomainCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	// ids of contacts created in the same apply are unknown while planning. They are only validated once known, and
+	// the whois protection, which depends on the registrant, is known after the domain is read again.
+	if d.Id() != "" && (!d.NewValueKnown("contacts") || d.HasChange("contacts")) {
+		if err := d.SetNewComputed("whois_protection_active"); err != nil {
+			return err
+		}
+	}
+
+	if !d.NewValueKnown("period") || !d.NewValueKnown("period_unit") {
+		return nil
+	}
+
+	period := d.Get("period").(string)
+	periodUnit := d.Get("period_unit").(string)
+	rawConfig := d.GetRawConfig()
+	if suffix := strings.TrimLeft(period, "0123456789"); suffix != "" && suffix != periodUnit &&
+		!rawConfig.IsNull() && !rawConfig.GetAttr("period_unit").IsNull() {
+		return fmt.Errorf("period %s does not match period_unit %s", period, periodUnit)
+	}
+	period = expandDomainPeriod(period, periodUnit)
+
+	if !d.Get("validate_period").(bool) || !d.NewValueKnown("name") {
+		return nil
+	}
+	if d.Id() != "" && !d.HasChanges("period", "period_unit") {
+		return nil
+	}
+
+	tld := domainTld(d.Get("name").(string))
+	if tld == "" {
+		return nil
+	}
+
+	price, call, err := m.(*api.Client).TldPrice(ctx, tld)
+	if err != nil {
+		return fmt.Errorf("could not get the periods of TLD '%s': %w", tld, err)
+	}
+	if price == nil {
+		return fmt.Errorf("could not get the periods of TLD '%s': %s", tld, call.ErrorMessage())
+	}
+
+	periods := api.TldPeriods(price)
+	if len(periods) == 0 {
+		return nil
+	}
+
+	months, _ := api.PeriodInMonths(period)
+	for _, supported := range periods {
+		if supportedMonths, ok := api.PeriodInMonths(supported); ok && supportedMonths == months {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("period %s is not supported by TLD '%s'. Supported periods: %s. Set validate_period to false to skip this check",
+		period, tld, strings.Join(periods, ", "))
+}
+
+// setDomainTransferLock enables or disables the transfer lock of a domain.
+func setDomainTransferLock(ctx context.Context, client *api.Client, domain string, lock bool) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	call, err := client.Call(ctx, "domain.update", map[string]interface{}{
+		"domain":       domain,
+		"transferLock": lock,
+	})
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not update transfer lock",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	if !call.IsSuccess() {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not update transfer lock",
+			Detail:   fmt.Sprintf("API response not status code 1000 or 1001. Got response: %s", call.ErrorMessage()),
+		})
+		return diags
+	}
+
+	return diags
+}
+
+func resourceDomainTrade(ctx context.Context, client *api.Client, domain string, registrant interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	parameters := map[string]interface{}{
+		"domain":     domain,
+		"registrant": registrant,
+	}
+
+	call, err := client.Call(ctx, "domain.trade", parameters)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not change domain registrant",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	if !call.IsSuccess() {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not change domain registrant",
+			Detail:   fmt.Sprintf("API response not status code 1000 or 1001. Got response: %s", call.ErrorMessage()),
 		})
 		return diags
 	}
@@ -290,11 +615,11 @@ func resourceDomainDelete(ctx context.Context, d *schema.ResourceData, meta inte
 		})
 		return diags
 	}
-	if call.Code() != api.COMMAND_SUCCESSFUL && call.Code() != api.COMMAND_SUCCESSFUL_PENDING {
+	if !call.IsSuccess() {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
 			Summary:  "Could not delete domain",
-			Detail:   fmt.Sprintf("API response not status code 1000 pr 1001. Got response: %s", call.ApiError()),
+			Detail:   fmt.Sprintf("API response not status code 1000 pr 1001. Got response: %s", call.ErrorMessage()),
 		})
 		return diags
 	}
@@ -302,6 +627,147 @@ func resourceDomainDelete(ctx context.Context, d *schema.ResourceData, meta inte
 	return diags
 }
 
+// domainManagesNameservers returns manage_nameservers. Domains in state from before the attribute was added have no
+// value, they keep managing their nameservers.
+func domainManagesNameservers(d *schema.ResourceData) bool {
+	manage, ok := d.GetOkExists("manage_nameservers")
+	return !ok || manage.(bool)
+}
+
+// filterManagedExtraData restricts the extData returned by the api to the keys that are
+// already tracked in state, so that keys set by other tools or by the registry do not show up as drift.
+func filterManagedExtraData(managed map[string]interface{}, serverExtData interface{}) map[string]interface{} {
+	filtered := map[string]interface{}{}
+
+	server, ok := serverExtData.(map[string]interface{})
+	if !ok {
+		return filtered
+	}
+
+	for key := range managed {
+		if val, ok := server[key]; ok {
+			filtered[key] = fmt.Sprint(val)
+		}
+	}
+
+	return filtered
+}
+
+// mergeExtraData builds the extData parameter for domain.update. Keys that exist on the domain but were
+// never managed by terraform are sent unchanged, keys removed from the configuration are dropped and
+// configured keys take precedence.
+func mergeExtraData(ctx context.Context, client *api.Client, d *schema.ResourceData) (map[string]interface{}, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	parameters := map[string]interface{}{
+		"domain": d.Id(),
+		"wide":   2,
+	}
+
+	call, err := client.Call(ctx, "domain.info", parameters)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not get domain info",
+			Detail:   err.Error(),
+		})
+		return nil, diags
+	}
+	if call.Code() != api.COMMAND_SUCCESSFUL {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not get domain info",
+			Detail:   fmt.Sprintf("API response not status code 1000. Got response: %s", call.ErrorMessage()),
+		})
+		return nil, diags
+	}
+
+	var serverExtData interface{}
+	if resData, ok := call["resData"].(map[string]interface{}); ok {
+		serverExtData = resData["extData"]
+	}
+
+	oldExtData, newExtData := d.GetChange("extra_data")
+	return mergeManagedExtraData(oldExtData.(map[string]interface{}), newExtData.(map[string]interface{}), serverExtData), diags
+}
+
+// mergeManagedExtraData merges the configured extra data into the extra data returned by the api. Keys not in the
+// previous configuration are kept, keys removed from the configuration are dropped.
+func mergeManagedExtraData(oldExtData map[string]interface{}, newExtData map[string]interface{}, serverExtData interface{}) map[string]interface{} {
+	merged := map[string]interface{}{}
+	if serverExtData, ok := serverExtData.(map[string]interface{}); ok {
+		for key, val := range serverExtData {
+			if _, managed := oldExtData[key]; !managed {
+				merged[key] = val
+			}
+		}
+	}
+	for key, val := range newExtData {
+		merged[key] = val
+	}
+
+	return merged
+}
+
+// expandDomainPeriod appends the period unit to a period given as bare number, e.g. 12 with unit M is 12M.
+func expandDomainPeriod(period string, periodUnit string) string {
+	period = strings.ToUpper(strings.TrimSpace(period))
+	if _, err := strconv.Atoi(period); err == nil {
+		return period + periodUnit
+	}
+	return period
+}
+
+// normalizePeriod returns the configured period if it describes the same length as the period returned by the api,
+// so that different notations like 1Y and 12M do not cause a diff.
+func normalizePeriod(serverPeriod string, configuredPeriod string, periodUnit string) string {
+	serverMonths, ok := api.PeriodInMonths(serverPeriod)
+	if !ok {
+		return serverPeriod
+	}
+	if configuredMonths, ok := api.PeriodInMonths(expandDomainPeriod(configuredPeriod, periodUnit)); ok && configuredMonths == serverMonths {
+		return configuredPeriod
+	}
+	if _, err := strconv.Atoi(strings.TrimSpace(serverPeriod)); err == nil {
+		return fmt.Sprintf("%dY", serverMonths/12)
+	}
+	return serverPeriod
+}
+
+// domainTld returns everything after the first label of a domain name, e.g. "co.uk" for "example.co.uk".
+func domainTld(domain string) string {
+	parts := strings.SplitN(strings.TrimSuffix(domain, "."), ".", 2)
+	if len(parts) != 2 {
+		return ""
+	}
+	return strings.ToLower(parts[1])
+}
+
+// getTldPrice returns the price entry of a TLD from domain.getPrices.
+func getTldPrice(ctx context.Context, client *api.Client, tld string) (map[string]interface{}, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	price, call, err := client.TldPrice(ctx, tld)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not get domain prices",
+			Detail:   err.Error(),
+		})
+		return nil, diags
+	}
+	if price == nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not get domain prices",
+			Detail:   fmt.Sprintf("API response not status code 1000. Got response: %s", call.ErrorMessage()),
+		})
+		return nil, diags
+	}
+
+	return price, diags
+}
+
 func validateCountryCode(i interface{}, path cty.Path) diag.Diagnostics {
 	var diags diag.Diagnostics
 	countryCode := i.(string)
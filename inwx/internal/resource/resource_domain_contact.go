@@ -6,12 +6,18 @@ import (
 	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/inwx/terraform-provider-inwx/inwx/internal/api"
 	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
 )
 
+var phoneNumberRegexp = regexp.MustCompile(`^\+[0-9]{1,3}\.?[0-9]+$`)
+
+var birthDateRegexp = regexp.MustCompile(`^[0-9]{4}-[0-9]{2}-[0-9]{2}$`)
+
 type Contact struct {
 	Type            string
 	Name            string
@@ -25,6 +31,20 @@ type Contact struct {
 	FaxNumber       string
 	Email           string
 	Remarks         string
+	WhoisProtection bool
+	IdCard          string
+	BirthDate       string
+	VatNumber       string
+	CompanyNumber   string
+}
+
+// contactRegistryFields maps the registry specific attributes of a contact to the fields of the api. They are only
+// required by some registries and only sent when set.
+var contactRegistryFields = map[string]string{
+	"id_card":        "idcard",
+	"birth_date":     "birthdate",
+	"vat_number":     "vatNumber",
+	"company_number": "companyNumber",
 }
 
 func DomainContactResource() *schema.Resource {
@@ -39,8 +59,17 @@ func DomainContactResource() *schema.Resource {
 		ReadContext:   resourceContactRead,
 		UpdateContext: resourceContactUpdate,
 		DeleteContext: resourceContactDelete,
+		CustomizeDiff: resourceContactCustomizeDiff,
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: func(ctx context.Context, d *schema.ResourceData, i interface{}) ([]*schema.ResourceData, error) {
+				contactId, err := strconv.Atoi(strings.TrimSpace(d.Id()))
+				if err != nil {
+					return nil, fmt.Errorf("unexpected format of ID (%s), expected the numerical contact id", d.Id())
+				}
+				d.SetId(strconv.Itoa(contactId))
+
+				return []*schema.ResourceData{d}, nil
+			},
 		},
 		Schema: map[string]*schema.Schema{
 			"type": {
@@ -101,9 +130,10 @@ func DomainContactResource() *schema.Resource {
 				Description:      "Country code of the contact. Must be two characters",
 			},
 			"phone_number": {
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: "Phone number of the contact",
+				Type:         schema.TypeString,
+				Required:     true,
+				Description:  "Phone number of the contact in the format +CC.NUMBER, e.g. +49.123456789",
+				ValidateFunc: validation.StringMatch(phoneNumberRegexp, "must be a phone number in the format +CC.NUMBER, e.g. +49.123456789"),
 			},
 			"fax": {
 				Type:        schema.TypeString,
@@ -115,6 +145,39 @@ func DomainContactResource() *schema.Resource {
 				Required:    true,
 				Description: "Contact email address",
 			},
+			"whois_protection": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Computed:    true,
+				Description: "Whether the contact data should be hidden in the WHOIS. If not set, the default of the api is used and read back",
+			},
+			"id_card": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Number of the identity card of the contact. Required by some registries",
+			},
+			"birth_date": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "Birth date of the contact in the format YYYY-MM-DD. Required by some registries",
+				ValidateFunc: validation.StringMatch(birthDateRegexp, "must be a date in the format YYYY-MM-DD"),
+			},
+			"vat_number": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "VAT identification number of the organization. Required by some registries",
+			},
+			"company_number": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Company registration number of the organization. Required by some registries",
+			},
+			"extra_data": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Extra data of the contact, e.g. membership ids required by sponsored TLDs",
+			},
 			"remarks": {
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -137,6 +200,19 @@ func DomainContactResource() *schema.Resource {
 	}
 }
 
+func resourceContactCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !d.NewValueKnown("type") || !d.NewValueKnown("organization") {
+		return nil
+	}
+
+	contactType := d.Get("type").(string)
+	if (contactType == "ORG" || contactType == "ROLE") && d.Get("organization").(string) == "" {
+		return fmt.Errorf("organization is required for %s contacts", contactType)
+	}
+
+	return nil
+}
+
 func resourceContactCreate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 	client := meta.(*api.Client)
@@ -144,15 +220,19 @@ func resourceContactCreate(ctx context.Context, data *schema.ResourceData, meta
 	contact := expandContactFromResourceData(data)
 
 	parameters := map[string]interface{}{
-		"type":       contact.Type,
-		"name":       contact.Name,
-		"street":     contact.StreetAddress,
-		"city":       contact.City,
-		"pc":         contact.PostalCode,
-		"sp":         contact.StateProvince,
-		"cc":         contact.CountryCode,
-		"voice":      contact.PhoneNumber,
-		"email":      contact.Email,
+		"type":   contact.Type,
+		"name":   contact.Name,
+		"street": contact.StreetAddress,
+		"city":   contact.City,
+		"pc":     contact.PostalCode,
+		"sp":     contact.StateProvince,
+		"cc":     contact.CountryCode,
+		"voice":  contact.PhoneNumber,
+		"email":  contact.Email,
+	}
+	// without protection the api applies its default, which is read back
+	if !data.GetRawConfig().GetAttr("whois_protection").IsNull() {
+		parameters["protection"] = contact.WhoisProtection
 	}
 	if contact.Organization != "" {
 		parameters["org"] = contact.Organization
@@ -166,6 +246,14 @@ func resourceContactCreate(ctx context.Context, data *schema.ResourceData, meta
 	if contact.Remarks != "" {
 		parameters["remarks"] = contact.Remarks
 	}
+	for attribute, field := range contactRegistryFields {
+		if value, ok := data.GetOk(attribute); ok {
+			parameters[field] = value
+		}
+	}
+	if extraData, ok := data.GetOk("extra_data"); ok {
+		parameters["extData"] = extraData
+	}
 
 	call, err := client.Call(ctx, "contact.create", parameters)
 	if err != nil {
@@ -177,35 +265,48 @@ func resourceContactCreate(ctx context.Context, data *schema.ResourceData, meta
 		return diags
 	}
 
-	if call.Code() != api.COMMAND_SUCCESSFUL && call.Code() != api.COMMAND_SUCCESSFUL_PENDING {
+	if !call.IsSuccess() {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
 			Summary:  "Could not create contact",
-			Detail:   fmt.Sprintf("API response not status code 1000 or 1001. Got response: %s", call.ApiError()),
+			Detail:   fmt.Sprintf("API response not status code 1000 or 1001. Got response: %s", call.ErrorMessage()),
 		})
 		return diags
 	}
 
-	rawId := call["resData"].(map[string]interface{})["id"]
-	switch rawId.(type) {
-	case string:
-		// When contact already exists: id = string
-		data.SetId(rawId.(string))
-	case float64:
-		// When contact does not already exist: id = float64 ...
-		data.SetId(strconv.Itoa(int(rawId.(float64))))
-	default:
+	diags = append(diags, contactResponseWarnings(call, "Contact creation")...)
+
+	contactId, err := coerceContactId(call["resData"].(map[string]interface{})["id"])
+	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
 			Summary:  "Unknown type",
-			Detail:   fmt.Sprintf("API returned unknown type for contact id: %s", reflect.TypeOf(rawId)),
+			Detail:   err.Error(),
 		})
 		return diags
 	}
+	data.SetId(strconv.Itoa(contactId))
 
 	return diags
 }
 
+// coerceContactId converts a contact id returned by the api to an int. Depending on the call, ids are returned as
+// string (e.g. when the contact already exists) or as number.
+func coerceContactId(rawId interface{}) (int, error) {
+	switch id := rawId.(type) {
+	case string:
+		contactId, err := strconv.Atoi(id)
+		if err != nil {
+			return 0, fmt.Errorf("API returned non numerical contact id: %s", id)
+		}
+		return contactId, nil
+	case float64:
+		return int(id), nil
+	default:
+		return 0, fmt.Errorf("API returned unknown type for contact id: %s", reflect.TypeOf(rawId))
+	}
+}
+
 func resourceContactRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 	client := meta.(*api.Client)
@@ -233,22 +334,46 @@ func resourceContactRead(ctx context.Context, data *schema.ResourceData, meta in
 		})
 		return diags
 	}
-	if call.Code() != api.COMMAND_SUCCESSFUL && call.Code() != api.COMMAND_SUCCESSFUL_PENDING {
+	if call.Code() == 2303 { // Object does not exist
+		data.SetId("")
+		return diags
+	}
+	if !call.IsSuccess() {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
 			Summary:  "Could not get contact info",
-			Detail:   fmt.Sprintf("API response not status code 1000 or 1001. Got response: %s", call.ApiError()),
+			Detail:   fmt.Sprintf("API response not status code 1000 or 1001. Got response: %s", call.ErrorMessage()),
 		})
 		return diags
 	}
 
-	contact := expandContactFromInfoResponse(call["resData"].(map[string]interface{})["contact"].(map[string]interface{}))
+	var contactData map[string]interface{}
+	if resData, ok := call["resData"].(map[string]interface{}); ok {
+		contactData, _ = resData["contact"].(map[string]interface{})
+	}
+	if contactData == nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not get contact info",
+			Detail:   fmt.Sprintf("API response contains no contact. Got response: %s", call.ApiError()),
+		})
+		return diags
+	}
+
+	contact, err := expandContactFromInfoResponse(contactData)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not read contact info",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
 
 	data.Set("type", contact.Type)
 	data.Set("name", contact.Name)
-	if contact.Organization != "" {
-		data.Set("organization", contact.Organization)
-	}
+	// PERSON contacts are returned with an empty organization, which equals an unset organization in state
+	data.Set("organization", contact.Organization)
 	data.Set("street_address", contact.StreetAddress)
 	data.Set("city", contact.City)
 	data.Set("postal_code", contact.PostalCode)
@@ -261,9 +386,22 @@ func resourceContactRead(ctx context.Context, data *schema.ResourceData, meta in
 		data.Set("fax", contact.FaxNumber)
 	}
 	data.Set("email", contact.Email)
+	data.Set("whois_protection", contact.WhoisProtection)
 	if contact.Remarks != "" {
 		data.Set("remarks", contact.Remarks)
 	}
+	for attribute, value := range map[string]string{
+		"id_card":        contact.IdCard,
+		"birth_date":     contact.BirthDate,
+		"vat_number":     contact.VatNumber,
+		"company_number": contact.CompanyNumber,
+	} {
+		// registry specific fields are only returned for contacts that have them
+		if value != "" {
+			data.Set(attribute, value)
+		}
+	}
+	data.Set("extra_data", filterManagedExtraData(data.Get("extra_data").(map[string]interface{}), contactData["extData"]))
 
 	return diags
 }
@@ -316,6 +454,22 @@ func resourceContactUpdate(ctx context.Context, data *schema.ResourceData, meta
 	if data.HasChange("remarks") {
 		parameters["remarks"] = data.Get("remarks")
 	}
+	if data.HasChange("whois_protection") {
+		parameters["protection"] = data.Get("whois_protection")
+	}
+	for attribute, field := range contactRegistryFields {
+		if data.HasChange(attribute) {
+			parameters[field] = data.Get(attribute)
+		}
+	}
+	if data.HasChange("extra_data") {
+		extData, extDataDiags := mergeContactExtraData(ctx, client, data)
+		diags = append(diags, extDataDiags...)
+		if diags.HasError() {
+			return diags
+		}
+		parameters["extData"] = extData
+	}
 
 	call, err := client.Call(ctx, "contact.update", parameters)
 	if err != nil {
@@ -327,15 +481,46 @@ func resourceContactUpdate(ctx context.Context, data *schema.ResourceData, meta
 		return diags
 	}
 
-	if call.Code() != api.COMMAND_SUCCESSFUL && call.Code() != api.COMMAND_SUCCESSFUL_PENDING {
+	if !call.IsSuccess() {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
 			Summary:  "Could not update contact",
-			Detail:   fmt.Sprintf("API response not status code 1000 or 1001. Got response: %s", call.ApiError()),
+			Detail:   fmt.Sprintf("API response not status code 1000 or 1001. Got response: %s", call.ErrorMessage()),
 		})
 		return diags
 	}
 
+	diags = append(diags, contactResponseWarnings(call, "Contact update")...)
+
+	// the api normalizes some values like phone numbers, read them back to avoid a diff after the update
+	if client.Testing {
+		return diags
+	}
+
+	return append(diags, resourceContactRead(ctx, data, meta)...)
+}
+
+// contactResponseWarnings returns warnings for a successful contact.create or contact.update response that is
+// pending, e.g. because the registry verifies the contact, or that contains a reason from the api.
+func contactResponseWarnings(call api.Response, operation string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	reason, _ := call["reason"].(string)
+	if call.IsPending() {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  operation + " is pending",
+			Detail: fmt.Sprintf("The api accepted the request, but it is not completed yet. The contact may have to be "+
+				"verified before it can be used for domains. Got response: %s", call.ErrorMessage()),
+		})
+	} else if reason != "" {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  operation + " returned a warning",
+			Detail:   fmt.Sprintf("Got response: %s", call.ErrorMessage()),
+		})
+	}
+
 	return diags
 }
 
@@ -365,11 +550,11 @@ func resourceContactDelete(ctx context.Context, data *schema.ResourceData, meta
 		return diags
 	}
 
-	if call.Code() != api.COMMAND_SUCCESSFUL && call.Code() != api.COMMAND_SUCCESSFUL_PENDING {
+	if !call.IsSuccess() {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
 			Summary:  "Could not delete contact",
-			Detail:   fmt.Sprintf("API response not status code 1000 or 1001. Got response: %s", call.ApiError()),
+			Detail:   fmt.Sprintf("API response not status code 1000 or 1001. Got response: %s", call.ErrorMessage()),
 		})
 		return diags
 	}
@@ -408,39 +593,93 @@ func expandContactFromResourceData(data *schema.ResourceData) *Contact {
 		FaxNumber:       fax,
 		Email:           data.Get("email").(string),
 		Remarks:         remarks,
+		WhoisProtection: data.Get("whois_protection").(bool),
 	}
 }
 
-func expandContactFromInfoResponse(contactData map[string]interface{}) *Contact {
-	var organization string
-	if dataOrganization, ok := contactData["org"]; ok {
-		organization = dataOrganization.(string)
-	}
-	var stateProvince string
-	if dataStateProvince, ok := contactData["sp"]; ok {
-		stateProvince = dataStateProvince.(string)
-	}
-	var fax string
-	if dataFax, ok := contactData["fax"]; ok {
-		fax = dataFax.(string)
+// expandContactFromInfoResponse converts the contact of a contact.info response. Optional fields may be missing or
+// null, required fields have to be strings.
+func expandContactFromInfoResponse(contactData map[string]interface{}) (*Contact, error) {
+	requiredString := func(key string) (string, error) {
+		value, ok := contactData[key].(string)
+		if !ok {
+			return "", fmt.Errorf("contact field '%s' is missing or not a string: %v", key, contactData[key])
+		}
+		return value, nil
+	}
+	optionalString := func(key string) string {
+		value, _ := contactData[key].(string)
+		return value
+	}
+
+	contact := &Contact{
+		Organization:    optionalString("org"),
+		StateProvince:   optionalString("sp"),
+		FaxNumber:       optionalString("fax"),
+		Remarks:         optionalString("remarks"),
+		WhoisProtection: api.IsTruthy(contactData["protection"]),
+		IdCard:          optionalString("idcard"),
+		BirthDate:       optionalString("birthdate"),
+		VatNumber:       optionalString("vatNumber"),
+		CompanyNumber:   optionalString("companyNumber"),
+	}
+	// the birth date may be returned with time
+	if len(contact.BirthDate) > 10 {
+		contact.BirthDate = contact.BirthDate[:10]
+	}
+
+	var err error
+	for key, field := range map[string]*string{
+		"type":   &contact.Type,
+		"name":   &contact.Name,
+		"street": &contact.StreetAddress,
+		"city":   &contact.City,
+		"pc":     &contact.PostalCode,
+		"cc":     &contact.CountryCode,
+		"voice":  &contact.PhoneNumber,
+		"email":  &contact.Email,
+	} {
+		if *field, err = requiredString(key); err != nil {
+			return nil, err
+		}
+	}
+
+	return contact, nil
+}
+
+// mergeContactExtraData builds the extData parameter for contact.update. contact.update replaces the extra data of
+// the contact, so keys that were never managed by terraform are sent unchanged.
+func mergeContactExtraData(ctx context.Context, client *api.Client, data *schema.ResourceData) (map[string]interface{}, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	call, err := client.Call(ctx, "contact.info", map[string]interface{}{
+		"id":   data.Id(),
+		"wide": 2,
+	})
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not get contact info",
+			Detail:   err.Error(),
+		})
+		return nil, diags
 	}
-	var remarks string
-	if dataRemarks, ok := contactData["remarks"]; ok {
-		remarks = dataRemarks.(string)
+	if !call.IsSuccess() {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not get contact info",
+			Detail:   fmt.Sprintf("API response not status code 1000 or 1001. Got response: %s", call.ErrorMessage()),
+		})
+		return nil, diags
 	}
 
-	return &Contact{
-		Type:            contactData["type"].(string),
-		Name:            contactData["name"].(string),
-		Organization:    organization,
-		StreetAddress:   contactData["street"].(string),
-		City:            contactData["city"].(string),
-		PostalCode:      contactData["pc"].(string),
-		StateProvince:   stateProvince,
-		CountryCode:     contactData["cc"].(string),
-		PhoneNumber:     contactData["voice"].(string),
-		FaxNumber:       fax,
-		Email:           contactData["email"].(string),
-		Remarks:         remarks,
+	var serverExtData interface{}
+	if resData, ok := call["resData"].(map[string]interface{}); ok {
+		if contactData, ok := resData["contact"].(map[string]interface{}); ok {
+			serverExtData = contactData["extData"]
+		}
 	}
+
+	oldExtData, newExtData := data.GetChange("extra_data")
+	return mergeManagedExtraData(oldExtData.(map[string]interface{}), newExtData.(map[string]interface{}), serverExtData), diags
 }
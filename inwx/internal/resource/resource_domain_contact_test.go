@@ -0,0 +1,210 @@
+package resource
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/inwx/terraform-provider-inwx/inwx/internal/api/apitest"
+)
+
+// contactConfig returns the configuration of a PERSON contact with the given attributes added.
+func contactConfig(attributes map[string]interface{}) map[string]interface{} {
+	config := map[string]interface{}{
+		"type":           "PERSON",
+		"name":           "Erika Mustermann",
+		"street_address": "Example Street 1",
+		"city":           "Karlsruhe",
+		"postal_code":    "76133",
+		"country_code":   "DE",
+		"phone_number":   "+49.123456789",
+		"email":          "erika@example.com",
+	}
+	for key, value := range attributes {
+		config[key] = value
+	}
+	return config
+}
+
+// contactInfo returns a contact.info response of the contact of contactConfig with the given fields added.
+func contactInfo(fields map[string]interface{}) map[string]interface{} {
+	contact := map[string]interface{}{
+		"id":         42,
+		"type":       "PERSON",
+		"name":       "Erika Mustermann",
+		"street":     "Example Street 1",
+		"city":       "Karlsruhe",
+		"pc":         "76133",
+		"cc":         "DE",
+		"voice":      "+49.123456789",
+		"email":      "erika@example.com",
+		"protection": 1,
+	}
+	for key, value := range fields {
+		contact[key] = value
+	}
+	return apitest.Success(map[string]interface{}{"contact": contact})
+}
+
+func TestResourceContactCreateWhoisProtection(t *testing.T) {
+	tests := []struct {
+		name       string
+		attributes map[string]interface{}
+		want       interface{}
+	}{
+		{name: "unset", attributes: nil, want: nil},
+		{name: "disabled", attributes: map[string]interface{}{"whois_protection": false}, want: false},
+		{name: "enabled", attributes: map[string]interface{}{"whois_protection": true}, want: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stub := apitest.NewStub()
+			stub.Respond("contact.create", apitest.Success(map[string]interface{}{"id": 42}))
+			client := stub.Client(t)
+
+			d := planData(t, DomainContactResource(), "", nil, contactConfig(test.attributes), client)
+			requireNoErrors(t, resourceContactCreate(context.Background(), d, client))
+
+			if got := stub.LastCall("contact.create")["protection"]; got != test.want {
+				t.Errorf("expected protection %v, got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestResourceContactImport(t *testing.T) {
+	tests := []struct {
+		id     string
+		wantId string
+	}{
+		{id: "42", wantId: "42"},
+		{id: " 42 ", wantId: "42"},
+		{id: "abc"},
+		{id: "42abc"},
+		{id: ""},
+	}
+
+	r := DomainContactResource()
+	for _, test := range tests {
+		d := stateData(t, r, test.id, nil)
+		imported, err := r.Importer.StateContext(context.Background(), d, nil)
+
+		if test.wantId == "" {
+			if err == nil || !strings.Contains(err.Error(), "expected the numerical contact id") {
+				t.Errorf("expected import of %q to fail, got %v", test.id, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("unexpected error importing %q: %v", test.id, err)
+			continue
+		}
+		if len(imported) != 1 || imported[0].Id() != test.wantId {
+			t.Errorf("expected import of %q to have id %s, got %v", test.id, test.wantId, imported)
+		}
+	}
+}
+
+func TestResourceContactReadEmptyOrganization(t *testing.T) {
+	stub := apitest.NewStub()
+	stub.Respond("contact.info", contactInfo(map[string]interface{}{"org": ""}))
+	client := stub.Client(t)
+
+	r := DomainContactResource()
+	d := stateData(t, r, "42", contactConfig(map[string]interface{}{"organization": "Example GmbH"}))
+	requireNoErrors(t, resourceContactRead(context.Background(), d, client))
+
+	if got := d.Get("organization"); got != "" {
+		t.Fatalf("expected the empty organization to be read, got %q", got)
+	}
+
+	state := map[string]interface{}{}
+	for key := range contactConfig(map[string]interface{}{"organization": "", "whois_protection": true}) {
+		state[key] = d.Get(key)
+	}
+	_, diff, err := diffResource(t, r, "42", state, contactConfig(nil), client)
+	if err != nil {
+		t.Fatalf("could not plan: %v", err)
+	}
+	if diff != nil && !diff.Empty() {
+		t.Errorf("expected no diff for a PERSON contact without organization, got %v", diff.Attributes)
+	}
+}
+
+func TestResourceContactCreateStringId(t *testing.T) {
+	stub := apitest.NewStub()
+	// an existing contact is returned with its id as string
+	stub.Respond("contact.create", apitest.Success(map[string]interface{}{"id": "42"}))
+	client := stub.Client(t)
+
+	d := planData(t, DomainContactResource(), "", nil, contactConfig(nil), client)
+	requireNoErrors(t, resourceContactCreate(context.Background(), d, client))
+
+	if d.Id() != "42" {
+		t.Errorf("expected id 42, got %s", d.Id())
+	}
+}
+
+func TestResourceContactUpdateReadsNormalizedValues(t *testing.T) {
+	stub := apitest.NewStub()
+	stub.Respond("contact.update", apitest.Success(nil))
+	stub.Respond("contact.info", contactInfo(map[string]interface{}{"voice": "+49.721123456"}))
+	client := stub.Client(t)
+
+	state := contactConfig(map[string]interface{}{"whois_protection": true})
+	config := contactConfig(map[string]interface{}{"phone_number": "+49 721 123456"})
+	d := planData(t, DomainContactResource(), "42", state, config, client)
+	requireNoErrors(t, resourceContactUpdate(context.Background(), d, client))
+
+	if got := stub.LastCall("contact.update")["voice"]; got != "+49 721 123456" {
+		t.Errorf("expected the configured phone number to be sent, got %v", got)
+	}
+	if len(stub.Calls("contact.info")) != 1 {
+		t.Fatal("expected the contact to be read after the update")
+	}
+	if got := d.Get("phone_number"); got != "+49.721123456" {
+		t.Errorf("expected the normalized phone number in state, got %v", got)
+	}
+}
+
+func TestResourceContactUpdateKeepsUnmanagedExtraData(t *testing.T) {
+	stub := apitest.NewStub()
+	stub.Respond("contact.info", contactInfo(map[string]interface{}{
+		"extData": map[string]interface{}{"XXX-MEMBER-ID": "1", "REMOVED": "1", "TRAVEL-INDUSTRY": "1"},
+	}))
+	stub.Respond("contact.update", apitest.Success(nil))
+	client := stub.Client(t)
+
+	state := contactConfig(map[string]interface{}{
+		"extra_data": map[string]interface{}{"XXX-MEMBER-ID": "1", "REMOVED": "1"},
+	})
+	config := contactConfig(map[string]interface{}{
+		"extra_data": map[string]interface{}{"XXX-MEMBER-ID": "2"},
+	})
+	d := planData(t, DomainContactResource(), "42", state, config, client)
+	requireNoErrors(t, resourceContactUpdate(context.Background(), d, client))
+
+	extData, _ := stub.LastCall("contact.update")["extData"].(map[string]interface{})
+	if extData["XXX-MEMBER-ID"] != "2" {
+		t.Errorf("expected the configured value to be sent, got %v", extData)
+	}
+	if extData["TRAVEL-INDUSTRY"] != "1" {
+		t.Errorf("expected the unmanaged key to be sent unchanged, got %v", extData)
+	}
+	if _, ok := extData["REMOVED"]; ok {
+		t.Errorf("expected the key removed from the configuration to be dropped, got %v", extData)
+	}
+}
+
+func TestResourceContactReadRemoved(t *testing.T) {
+	stub := apitest.NewStub()
+	stub.Respond("contact.info", apitest.Error(2303, "Object does not exist"))
+
+	d := stateData(t, DomainContactResource(), "42", contactConfig(nil))
+	requireNoErrors(t, resourceContactRead(context.Background(), d, stub.Client(t)))
+
+	if d.Id() != "" {
+		t.Errorf("expected a contact that does not exist to be removed from state, got %q", d.Id())
+	}
+}
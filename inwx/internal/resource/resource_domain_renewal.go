@@ -0,0 +1,158 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/inwx/terraform-provider-inwx/inwx/internal/api"
+)
+
+func DomainRenewalResource() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceDomainRenewalCreate,
+		ReadContext:   resourceDomainRenewalRead,
+		DeleteContext: resourceDomainRenewalDelete,
+		Schema: map[string]*schema.Schema{
+			"domain": {
+				Description: "Name of the domain to renew",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+			"period": {
+				Description:  "Renewal period, e.g. 1Y or 12M",
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringMatch(api.PeriodRegexp, "must be a number followed by Y (years) or M (months), e.g. 1Y"),
+			},
+			"expiration_date": {
+				Description: "Expiration date of the domain after the renewal",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func resourceDomainRenewalCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	client := m.(*api.Client)
+
+	domain := d.Get("domain").(string)
+
+	// domain.renew requires the current expiration date, so that a domain is not renewed twice by accident
+	expiration, exists, expirationDiags := domainExpirationDate(ctx, client, domain)
+	if expirationDiags.HasError() {
+		return expirationDiags
+	}
+	if !exists {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not renew domain",
+			Detail:   fmt.Sprintf("The domain %s does not exist in the account", domain),
+		})
+		return diags
+	}
+
+	parameters := map[string]interface{}{
+		"domain":     domain,
+		"period":     d.Get("period").(string),
+		"expiration": expiration,
+	}
+
+	call, err := client.Call(ctx, "domain.renew", parameters)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not renew domain",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	if !call.IsSuccess() {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not renew domain",
+			Detail:   fmt.Sprintf("API response not status code 1000 or 1001. Got response: %s", call.ErrorMessage()),
+		})
+		return diags
+	}
+
+	d.SetId(fmt.Sprintf("%s:%s", domain, expiration))
+
+	if client.Testing {
+		return diags
+	}
+
+	return resourceDomainRenewalRead(ctx, d, m)
+}
+
+func resourceDomainRenewalRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*api.Client)
+
+	expiration, exists, diags := domainExpirationDate(ctx, client, d.Get("domain").(string))
+	if diags.HasError() {
+		return diags
+	}
+	if !exists {
+		d.SetId("")
+		return diags
+	}
+	d.Set("expiration_date", expiration)
+
+	return diags
+}
+
+// resourceDomainRenewalDelete only removes the renewal from the state, a renewal cannot be undone.
+func resourceDomainRenewalDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	d.SetId("")
+
+	return diags
+}
+
+// domainExpirationDate returns the expiration date of a domain from domain.info as YYYY-MM-DD and whether the domain
+// exists.
+func domainExpirationDate(ctx context.Context, client *api.Client, domain string) (string, bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	call, err := client.Call(ctx, "domain.info", map[string]interface{}{
+		"domain": domain,
+	})
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not get domain info",
+			Detail:   err.Error(),
+		})
+		return "", false, diags
+	}
+	if call.Code() == 2303 { // Object does not exist
+		return "", false, diags
+	}
+	if call.Code() != api.COMMAND_SUCCESSFUL {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not get domain info",
+			Detail:   fmt.Sprintf("API response not status code 1000. Got response: %s", call.ErrorMessage()),
+		})
+		return "", false, diags
+	}
+
+	resData, _ := call["resData"].(map[string]interface{})
+	expiration, ok := resData["exDate"].(string)
+	if !ok || len(expiration) < 10 {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not get domain expiration date",
+			Detail:   fmt.Sprintf("API response contains no expiration date. Got response: %s", call.ApiError()),
+		})
+		return "", false, diags
+	}
+
+	return expiration[:10], true, diags
+}
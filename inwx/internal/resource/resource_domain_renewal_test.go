@@ -0,0 +1,32 @@
+package resource
+
+import (
+	"context"
+	"testing"
+
+	"github.com/inwx/terraform-provider-inwx/inwx/internal/api/apitest"
+)
+
+func TestResourceDomainRenewalRead(t *testing.T) {
+	stub := apitest.NewStub()
+	stub.Respond("domain.info", domainInfo(map[string]interface{}{"exDate": "2026-03-01T00:00:00Z"}))
+
+	d := stateData(t, DomainRenewalResource(), "example.com:2025-03-01", map[string]interface{}{"domain": "example.com"})
+	requireNoErrors(t, resourceDomainRenewalRead(context.Background(), d, stub.Client(t)))
+
+	if got := d.Get("expiration_date"); got != "2026-03-01" {
+		t.Errorf("expected expiration_date 2026-03-01, got %v", got)
+	}
+}
+
+func TestResourceDomainRenewalReadRemoved(t *testing.T) {
+	stub := apitest.NewStub()
+	stub.Respond("domain.info", apitest.Error(2303, "Object does not exist"))
+
+	d := stateData(t, DomainRenewalResource(), "example.com:2025-03-01", map[string]interface{}{"domain": "example.com"})
+	requireNoErrors(t, resourceDomainRenewalRead(context.Background(), d, stub.Client(t)))
+
+	if d.Id() != "" {
+		t.Errorf("expected the renewal of a removed domain to be removed from state, got id %s", d.Id())
+	}
+}
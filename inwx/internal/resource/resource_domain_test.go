@@ -0,0 +1,601 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/inwx/terraform-provider-inwx/inwx/internal/api/apitest"
+)
+
+// domainInfo returns a domain.info response of example.com with the given fields added to resData.
+func domainInfo(fields map[string]interface{}) map[string]interface{} {
+	resData := map[string]interface{}{
+		"domain":      "example.com",
+		"roId":        123456,
+		"ns":          []interface{}{"ns.inwx.de", "ns2.inwx.de"},
+		"period":      "1Y",
+		"renewalMode": "AUTORENEW",
+		"registrant":  1,
+		"admin":       2,
+		"tech":        3,
+		"billing":     4,
+	}
+	for key, value := range fields {
+		resData[key] = value
+	}
+	return apitest.Success(resData)
+}
+
+// domainConfig returns the configuration of example.com with the given attributes added.
+func domainConfig(attributes map[string]interface{}) map[string]interface{} {
+	config := map[string]interface{}{
+		"name":            "example.com",
+		"period":          "1Y",
+		"validate_period": false,
+		"nameservers":     []interface{}{"ns.inwx.de", "ns2.inwx.de"},
+		"contacts": []interface{}{map[string]interface{}{
+			"registrant": 1,
+			"admin":      2,
+			"tech":       3,
+			"billing":    4,
+		}},
+	}
+	for key, value := range attributes {
+		config[key] = value
+	}
+	return config
+}
+
+// domainState returns the state of example.com after applying domainConfig with the given attributes added.
+func domainState(attributes map[string]interface{}) map[string]interface{} {
+	state := domainConfig(attributes)
+	if _, ok := state["period_unit"]; !ok {
+		state["period_unit"] = "Y"
+	}
+	return state
+}
+
+func domainContacts(d *schema.ResourceData) map[string]interface{} {
+	return d.Get("contacts").(*schema.Set).List()[0].(map[string]interface{})
+}
+
+func TestResourceDomainRead(t *testing.T) {
+	stub := apitest.NewStub()
+	stub.Respond("domain.info", domainInfo(map[string]interface{}{
+		"transferLock": 1,
+	}))
+
+	d := stateData(t, DomainResource(), "example.com", map[string]interface{}{"name": "example.com"})
+	requireNoErrors(t, resourceDomainRead(context.Background(), d, stub.Client(t)))
+
+	if params := stub.LastCall("domain.info"); params["domain"] != "example.com" || params["wide"] != float64(2) {
+		t.Errorf("unexpected domain.info parameters %v", params)
+	}
+	if got := d.Get("nameservers").(*schema.Set).Len(); got != 2 {
+		t.Errorf("expected 2 nameservers, got %d", got)
+	}
+	if got := d.Get("period"); got != "1Y" {
+		t.Errorf("expected period 1Y, got %v", got)
+	}
+	if got := d.Get("renewal_mode"); got != "AUTORENEW" {
+		t.Errorf("expected renewal_mode AUTORENEW, got %v", got)
+	}
+	if got := d.Get("transfer_lock"); got != true {
+		t.Errorf("expected transfer_lock, got %v", got)
+	}
+	contacts := domainContacts(d)
+	for role, id := range map[string]int{"registrant": 1, "admin": 2, "tech": 3, "billing": 4} {
+		if contacts[role] != id {
+			t.Errorf("expected %s contact %d, got %v", role, id, contacts[role])
+		}
+	}
+}
+
+func TestResourceDomainReadRenewalCost(t *testing.T) {
+	for _, fetch := range []bool{true, false} {
+		t.Run(fmt.Sprintf("fetch_renewal_cost %t", fetch), func(t *testing.T) {
+			stub := apitest.NewStub()
+			stub.Respond("domain.info", domainInfo(nil))
+			stub.Respond("domain.getPrices", apitest.Success(map[string]interface{}{
+				"price": []interface{}{
+					map[string]interface{}{"tld": "com", "renewalPrice": 12.5, "currency": "EUR"},
+				},
+			}))
+
+			d := stateData(t, DomainResource(), "example.com", domainState(map[string]interface{}{"fetch_renewal_cost": fetch}))
+			requireNoErrors(t, resourceDomainRead(context.Background(), d, stub.Client(t)))
+
+			if !fetch {
+				if calls := stub.Calls("domain.getPrices"); len(calls) != 0 {
+					t.Errorf("expected no price lookup without fetch_renewal_cost, got %v", calls)
+				}
+				if _, ok := d.GetOk("renewal_cost"); ok {
+					t.Errorf("expected no renewal_cost without fetch_renewal_cost, got %v", d.Get("renewal_cost"))
+				}
+				return
+			}
+			if tlds, _ := stub.LastCall("domain.getPrices")["tld"].([]interface{}); len(tlds) != 1 || tlds[0] != "com" {
+				t.Fatalf("expected the price of the TLD com to be looked up, got %v", stub.Calls("domain.getPrices"))
+			}
+			if got := d.Get("renewal_cost"); got != 12.5 {
+				t.Errorf("expected renewal_cost 12.5, got %v", got)
+			}
+			if got := d.Get("renewal_currency"); got != "EUR" {
+				t.Errorf("expected renewal_currency EUR, got %v", got)
+			}
+		})
+	}
+}
+
+func TestResourceDomainReadRenewalModeOfServer(t *testing.T) {
+	stub := apitest.NewStub()
+	stub.Respond("domain.info", domainInfo(map[string]interface{}{"renewalMode": "AUTODELETE"}))
+	client := stub.Client(t)
+
+	d := stateData(t, DomainResource(), "example.com", domainState(map[string]interface{}{"renewal_mode": "AUTORENEW"}))
+	requireNoErrors(t, resourceDomainRead(context.Background(), d, client))
+	if got := d.Get("renewal_mode"); got != "AUTODELETE" {
+		t.Fatalf("expected the renewal mode of the server, got %v", got)
+	}
+
+	// without renewal_mode in the configuration, the default of create does not override the server value
+	state := domainState(map[string]interface{}{"renewal_mode": "AUTODELETE"})
+	_, diff, err := diffResource(t, DomainResource(), "example.com", state, domainConfig(nil), client)
+	if err != nil {
+		t.Fatalf("could not plan: %v", err)
+	}
+	if diff != nil && diff.Attributes["renewal_mode"] != nil {
+		t.Errorf("expected no renewal_mode diff, got %v", diff.Attributes["renewal_mode"])
+	}
+}
+
+func TestResourceDomainReadWithoutTransferLock(t *testing.T) {
+	stub := apitest.NewStub()
+	stub.Respond("domain.info", domainInfo(nil))
+
+	d := stateData(t, DomainResource(), "example.com", map[string]interface{}{"name": "example.com", "transfer_lock": true})
+	requireNoErrors(t, resourceDomainRead(context.Background(), d, stub.Client(t)))
+
+	if got := d.Get("transfer_lock"); got != false {
+		t.Errorf("expected transfer_lock false for a domain without transfer lock, got %v", got)
+	}
+}
+
+func TestResourceDomainReadApiError(t *testing.T) {
+	stub := apitest.NewStub()
+	stub.Respond("domain.info", apitest.Error(2400, "Command failed"))
+
+	d := stateData(t, DomainResource(), "example.com", map[string]interface{}{"name": "example.com"})
+	diags := resourceDomainRead(context.Background(), d, stub.Client(t))
+	if !diags.HasError() {
+		t.Fatal("expected error")
+	}
+	if got, want := diags[0].Detail, "API response not status code 1000. Got response: Command failed (code 2400)"; got != want {
+		t.Errorf("expected detail %q, got %q", want, got)
+	}
+}
+
+func TestResourceDomainCreate(t *testing.T) {
+	stub := apitest.NewStub()
+	stub.Respond("domain.create", apitest.Success(nil))
+	client := stub.Client(t)
+
+	d := planData(t, DomainResource(), "", nil, domainConfig(map[string]interface{}{
+		"period_unit": "M",
+		"period":      "12",
+	}), client)
+	requireNoErrors(t, resourceDomainCreate(context.Background(), d, client))
+
+	if d.Id() != "example.com" {
+		t.Errorf("expected id example.com, got %s", d.Id())
+	}
+	params := stub.LastCall("domain.create")
+	if params["domain"] != "example.com" || params["period"] != "12M" || params["renewalMode"] != "AUTORENEW" {
+		t.Errorf("unexpected domain.create parameters %v", params)
+	}
+	for role, id := range map[string]float64{"registrant": 1, "admin": 2, "tech": 3, "billing": 4} {
+		if params[role] != id {
+			t.Errorf("expected %s contact %v, got %v", role, id, params[role])
+		}
+	}
+	if ns, _ := params["ns"].([]interface{}); len(ns) != 2 {
+		t.Errorf("expected 2 nameservers, got %v", params["ns"])
+	}
+	if _, ok := params["transferLock"]; ok {
+		t.Errorf("expected no transferLock for an unset transfer_lock, got %v", params["transferLock"])
+	}
+}
+
+func TestResourceDomainCreateWithContactOfSameApply(t *testing.T) {
+	stub := apitest.NewStub()
+	stub.Respond("contact.create", apitest.Success(map[string]interface{}{"id": 42}))
+	stub.Respond("contact.info", contactInfo(nil))
+	stub.Respond("domain.create", apitest.Success(nil))
+	client := stub.Client(t)
+
+	contacts := map[string]interface{}{"registrant": unknownValue, "admin": 2, "tech": 3, "billing": 4}
+	config := domainConfig(map[string]interface{}{"contacts": []interface{}{contacts}})
+
+	// the id of the registrant is unknown until the contact is created
+	diff, err := DomainResource().Diff(context.Background(), nil, terraform.NewResourceConfigRaw(config), client)
+	if err != nil {
+		t.Fatalf("could not plan with an unknown contact id: %v", err)
+	}
+	unknown := false
+	for key, attribute := range diff.Attributes {
+		if strings.HasPrefix(key, "contacts.") && strings.HasSuffix(key, ".registrant") && attribute.NewComputed {
+			unknown = true
+		}
+	}
+	if !unknown {
+		t.Fatalf("expected the registrant to be unknown in the plan, got %v", diff.Attributes)
+	}
+
+	contact := planData(t, DomainContactResource(), "", nil, contactConfig(nil), client)
+	requireNoErrors(t, resourceContactCreate(context.Background(), contact, client))
+
+	contacts["registrant"], _ = strconv.Atoi(contact.Id())
+	d := planData(t, DomainResource(), "", nil, config, client)
+	requireNoErrors(t, resourceDomainCreate(context.Background(), d, client))
+
+	if got := stub.LastCall("domain.create")["registrant"]; got != float64(42) {
+		t.Errorf("expected the id of the created contact as registrant, got %v", got)
+	}
+}
+
+func TestResourceDomainPlanUnknownRegistrant(t *testing.T) {
+	contacts := map[string]interface{}{"registrant": unknownValue, "admin": 2, "tech": 3, "billing": 4}
+	config := domainConfig(map[string]interface{}{"contacts": []interface{}{contacts}})
+	state := domainState(map[string]interface{}{"whois_protection_active": true})
+
+	instanceState := stateData(t, DomainResource(), "example.com", state).State()
+	diff, err := DomainResource().Diff(context.Background(), instanceState, terraform.NewResourceConfigRaw(config), apitest.NewStub().Client(t))
+	if err != nil {
+		t.Fatalf("could not plan with an unknown contact id: %v", err)
+	}
+	if attribute := diff.Attributes["whois_protection_active"]; attribute == nil || !attribute.NewComputed {
+		t.Errorf("expected whois_protection_active to be unknown for a new registrant, got %v", attribute)
+	}
+}
+
+func TestResourceDomainCreateApiError(t *testing.T) {
+	stub := apitest.NewStub()
+	stub.Respond("domain.create", apitest.Error(2302, "Object exists"))
+	client := stub.Client(t)
+
+	d := planData(t, DomainResource(), "", nil, domainConfig(nil), client)
+	diags := resourceDomainCreate(context.Background(), d, client)
+	if !diags.HasError() {
+		t.Fatal("expected error")
+	}
+	if d.Id() != "" {
+		t.Errorf("expected no id after a failed create, got %s", d.Id())
+	}
+}
+
+func TestResourceDomainReadIgnoresUnmanagedExtraData(t *testing.T) {
+	stub := apitest.NewStub()
+	stub.Respond("domain.info", domainInfo(map[string]interface{}{
+		"extData": map[string]interface{}{"ACCEPT-TRUSTEE-TAC": "1", "WHOIS-PROTECTION": "0"},
+	}))
+
+	d := stateData(t, DomainResource(), "example.com", map[string]interface{}{
+		"name":       "example.com",
+		"extra_data": map[string]interface{}{"ACCEPT-TRUSTEE-TAC": "1"},
+	})
+	requireNoErrors(t, resourceDomainRead(context.Background(), d, stub.Client(t)))
+
+	if got := d.Get("extra_data").(map[string]interface{}); len(got) != 1 || got["ACCEPT-TRUSTEE-TAC"] != "1" {
+		t.Errorf("expected only the managed extra data, got %v", got)
+	}
+}
+
+func TestResourceDomainUpdateKeepsUnmanagedExtraData(t *testing.T) {
+	stub := apitest.NewStub()
+	stub.Respond("domain.info", domainInfo(map[string]interface{}{
+		"extData": map[string]interface{}{"ACCEPT-TRUSTEE-TAC": "1", "REMOVED": "1", "WHOIS-PROTECTION": "1"},
+	}))
+	stub.Respond("domain.update", apitest.Success(nil))
+	client := stub.Client(t)
+
+	state := domainState(map[string]interface{}{
+		"extra_data": map[string]interface{}{"ACCEPT-TRUSTEE-TAC": "1", "REMOVED": "1"},
+	})
+	config := domainConfig(map[string]interface{}{
+		"extra_data": map[string]interface{}{"ACCEPT-TRUSTEE-TAC": "0"},
+	})
+	d := planData(t, DomainResource(), "example.com", state, config, client)
+	requireNoErrors(t, resourceDomainUpdate(context.Background(), d, client))
+
+	params := stub.LastCall("domain.update")
+	extData, _ := params["extData"].(map[string]interface{})
+	if extData["ACCEPT-TRUSTEE-TAC"] != "0" {
+		t.Errorf("expected the configured value to be sent, got %v", extData)
+	}
+	if extData["WHOIS-PROTECTION"] != "1" {
+		t.Errorf("expected the unmanaged key to be sent unchanged, got %v", extData)
+	}
+	if _, ok := extData["REMOVED"]; ok {
+		t.Errorf("expected the key removed from the configuration to be dropped, got %v", extData)
+	}
+}
+
+func TestResourceDomainUpdateContacts(t *testing.T) {
+	stub := apitest.NewStub()
+	stub.Respond("domain.update", apitest.Success(nil))
+	stub.Respond("domain.trade", apitest.Success(nil))
+	client := stub.Client(t)
+
+	d := planData(t, DomainResource(), "example.com", domainState(nil), domainConfig(map[string]interface{}{
+		"contacts": []interface{}{map[string]interface{}{"registrant": 1, "admin": 2, "tech": 5, "billing": 4}},
+	}), client)
+	requireNoErrors(t, resourceDomainUpdate(context.Background(), d, client))
+
+	if calls := stub.Calls("domain.trade"); len(calls) != 0 {
+		t.Errorf("expected no domain.trade for an unchanged registrant, got %v", calls)
+	}
+	params := stub.LastCall("domain.update")
+	if params["tech"] != float64(5) {
+		t.Errorf("expected the new tech contact in domain.update, got %v", params)
+	}
+	for _, role := range []string{"registrant", "admin", "billing"} {
+		if _, ok := params[role]; ok {
+			t.Errorf("expected no unchanged %s contact in domain.update, got %v", role, params)
+		}
+	}
+}
+
+func TestResourceDomainUpdateRegistrant(t *testing.T) {
+	stub := apitest.NewStub()
+	stub.Respond("domain.update", apitest.Success(nil))
+	stub.Respond("domain.trade", apitest.Pending(nil))
+	client := stub.Client(t)
+
+	d := planData(t, DomainResource(), "example.com", domainState(nil), domainConfig(map[string]interface{}{
+		"contacts": []interface{}{map[string]interface{}{"registrant": 5, "admin": 2, "tech": 3, "billing": 4}},
+	}), client)
+	requireNoErrors(t, resourceDomainUpdate(context.Background(), d, client))
+
+	if params := stub.LastCall("domain.trade"); params["domain"] != "example.com" || params["registrant"] != float64(5) {
+		t.Errorf("unexpected domain.trade parameters %v", params)
+	}
+	if calls := stub.Calls("domain.update"); len(calls) != 0 {
+		t.Errorf("expected no domain.update when only the registrant changed, got %v", calls)
+	}
+}
+
+func TestResourceDomainUnsetNameservers(t *testing.T) {
+	stub := apitest.NewStub()
+	stub.Respond("domain.create", apitest.Success(nil))
+	stub.Respond("domain.info", domainInfo(map[string]interface{}{
+		"ns": []interface{}{"ns1.example.net", "ns2.example.net"},
+	}))
+	client := stub.Client(t)
+
+	config := domainConfig(nil)
+	delete(config, "nameservers")
+
+	d := planData(t, DomainResource(), "", nil, config, client)
+	requireNoErrors(t, resourceDomainCreate(context.Background(), d, client))
+	if ns, ok := stub.LastCall("domain.create")["ns"]; ok {
+		t.Errorf("expected no ns for unset nameservers, got %v", ns)
+	}
+
+	requireNoErrors(t, resourceDomainRead(context.Background(), d, client))
+	nameservers := d.Get("nameservers").(*schema.Set)
+	if nameservers.Len() != 2 || !nameservers.Contains("ns1.example.net") {
+		t.Fatalf("expected the nameservers of the domain to be read, got %v", nameservers.List())
+	}
+
+	state := domainState(map[string]interface{}{"nameservers": nameservers.List()})
+	_, diff, err := diffResource(t, DomainResource(), "example.com", state, config, client)
+	if err != nil {
+		t.Fatalf("could not plan: %v", err)
+	}
+	if diff != nil {
+		for key := range diff.Attributes {
+			if strings.HasPrefix(key, "nameservers") {
+				t.Errorf("expected no nameservers diff for unset nameservers, got %s: %v", key, diff.Attributes[key])
+			}
+		}
+	}
+}
+
+func TestResourceDomainNameserverSetId(t *testing.T) {
+	stub := apitest.NewStub()
+	stub.Respond("domain.info", domainInfo(map[string]interface{}{"nsSetId": 7}))
+	client := stub.Client(t)
+
+	t.Run("not configured", func(t *testing.T) {
+		d := stateData(t, DomainResource(), "example.com", domainState(nil))
+		requireNoErrors(t, resourceDomainRead(context.Background(), d, client))
+		if got := d.Get("nameserver_set_id"); got != 0 {
+			t.Fatalf("expected the nameserver set of the api not to be read without nameserver_set_id, got %v", got)
+		}
+
+		_, diff, err := diffResource(t, DomainResource(), "example.com", domainState(nil), domainConfig(nil), client)
+		if err != nil {
+			t.Fatalf("could not plan: %v", err)
+		}
+		if diff != nil && diff.Attributes["nameserver_set_id"] != nil {
+			t.Errorf("expected no nameserver_set_id diff, got %v", diff.Attributes["nameserver_set_id"])
+		}
+	})
+
+	t.Run("configured", func(t *testing.T) {
+		state := domainState(map[string]interface{}{"nameserver_set_id": 5})
+		delete(state, "nameservers")
+		d := stateData(t, DomainResource(), "example.com", state)
+		requireNoErrors(t, resourceDomainRead(context.Background(), d, client))
+		if got := d.Get("nameserver_set_id"); got != 7 {
+			t.Errorf("expected the nameserver set 7 of the domain, got %v", got)
+		}
+	})
+}
+
+func TestNormalizePeriod(t *testing.T) {
+	tests := []struct {
+		server, configured, unit, want string
+	}{
+		{server: "12M", configured: "1Y", unit: "Y", want: "1Y"},
+		{server: "1Y", configured: "12", unit: "M", want: "12"},
+		{server: "1", configured: "1Y", unit: "Y", want: "1Y"},
+		{server: "2", configured: "1Y", unit: "Y", want: "2Y"},
+		{server: "24M", configured: "1Y", unit: "Y", want: "24M"},
+		{server: "unknown", configured: "1Y", unit: "Y", want: "unknown"},
+	}
+
+	for _, test := range tests {
+		if got := normalizePeriod(test.server, test.configured, test.unit); got != test.want {
+			t.Errorf("normalizePeriod(%q, %q, %q) = %q, want %q", test.server, test.configured, test.unit, got, test.want)
+		}
+	}
+}
+
+func TestResourceDomainReadPeriodInMonths(t *testing.T) {
+	stub := apitest.NewStub()
+	stub.Respond("domain.info", domainInfo(map[string]interface{}{"period": "12M"}))
+
+	d := stateData(t, DomainResource(), "example.com", domainState(nil))
+	requireNoErrors(t, resourceDomainRead(context.Background(), d, stub.Client(t)))
+
+	if got := d.Get("period"); got != "1Y" {
+		t.Errorf("expected the configured period 1Y for 12M, got %v", got)
+	}
+}
+
+func TestResourceDomainValidatePeriod(t *testing.T) {
+	stub := apitest.NewStub()
+	stub.Respond("domain.getPrices", apitest.Success(map[string]interface{}{
+		"price": []interface{}{map[string]interface{}{"tld": "com", "period": "1Y,2Y"}},
+	}))
+	client := stub.Client(t)
+
+	for period, wantError := range map[string]bool{"1Y": false, "24M": false, "3Y": true} {
+		_, _, err := diffResource(t, DomainResource(), "", nil, domainConfig(map[string]interface{}{
+			"period":          period,
+			"validate_period": true,
+		}), client)
+		if wantError && (err == nil || !strings.Contains(err.Error(), "Supported periods: 1Y, 2Y")) {
+			t.Errorf("expected period %s to be rejected, got %v", period, err)
+		}
+		if !wantError && err != nil {
+			t.Errorf("expected period %s to be accepted, got %v", period, err)
+		}
+	}
+}
+
+func TestDomainContactId(t *testing.T) {
+	tests := []struct {
+		name    string
+		resData map[string]interface{}
+		want    int
+	}{
+		{name: "number", resData: map[string]interface{}{"tech": float64(3)}, want: 3},
+		{name: "string", resData: map[string]interface{}{"tech": "3"}, want: 3},
+		{name: "contact object", resData: map[string]interface{}{
+			"contact": map[string]interface{}{"tech": map[string]interface{}{"id": "3"}},
+		}, want: 3},
+		{name: "contact id", resData: map[string]interface{}{
+			"contact": map[string]interface{}{"tech": float64(3)},
+		}, want: 3},
+	}
+
+	for _, test := range tests {
+		if got, err := domainContactId(test.resData, "tech"); err != nil || got != test.want {
+			t.Errorf("%s: expected contact id %d, got %d, %v", test.name, test.want, got, err)
+		}
+	}
+
+	for name, resData := range map[string]map[string]interface{}{
+		"missing":     {},
+		"not numeric": {"tech": "abc"},
+		"bool":        {"tech": true},
+	} {
+		if _, err := domainContactId(resData, "tech"); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestResourceDomainReadStringContactIds(t *testing.T) {
+	stub := apitest.NewStub()
+	stub.Respond("domain.info", domainInfo(map[string]interface{}{
+		"registrant": "1", "admin": "2", "tech": "3", "billing": "4",
+	}))
+
+	d := stateData(t, DomainResource(), "example.com", map[string]interface{}{"name": "example.com"})
+	requireNoErrors(t, resourceDomainRead(context.Background(), d, stub.Client(t)))
+
+	contacts := domainContacts(d)
+	for role, id := range map[string]int{"registrant": 1, "admin": 2, "tech": 3, "billing": 4} {
+		if contacts[role] != id {
+			t.Errorf("expected %s contact %d, got %v", role, id, contacts[role])
+		}
+	}
+}
+
+func TestWhoisProtectionActive(t *testing.T) {
+	tests := []struct {
+		name    string
+		resData map[string]interface{}
+		want    bool
+	}{
+		{name: "none", resData: map[string]interface{}{}, want: false},
+		{name: "extra data", resData: map[string]interface{}{
+			"extData": map[string]interface{}{"WHOIS-PROTECTION": "1"},
+		}, want: true},
+		{name: "extra data disabled", resData: map[string]interface{}{
+			"extData": map[string]interface{}{"WHOIS-PROTECTION": "0"},
+		}, want: false},
+		{name: "registrant", resData: map[string]interface{}{
+			"contact": map[string]interface{}{"registrant": map[string]interface{}{"id": 1, "protection": float64(1)}},
+		}, want: true},
+		{name: "registrant disabled", resData: map[string]interface{}{
+			"contact": map[string]interface{}{"registrant": map[string]interface{}{"id": 1, "protection": false}},
+		}, want: false},
+		{name: "other contact", resData: map[string]interface{}{
+			"contact": map[string]interface{}{"admin": map[string]interface{}{"id": 2, "protection": true}},
+		}, want: false},
+	}
+
+	for _, test := range tests {
+		if got := whoisProtectionActive(test.resData); got != test.want {
+			t.Errorf("%s: expected %v, got %v", test.name, test.want, got)
+		}
+	}
+}
+
+func TestResourceDomainReadWhoisProtectionActive(t *testing.T) {
+	stub := apitest.NewStub()
+	stub.Respond("domain.info", domainInfo(map[string]interface{}{
+		"extData": map[string]interface{}{"WHOIS-PROTECTION": "1"},
+	}))
+
+	d := stateData(t, DomainResource(), "example.com", map[string]interface{}{"name": "example.com"})
+	requireNoErrors(t, resourceDomainRead(context.Background(), d, stub.Client(t)))
+
+	if got := d.Get("whois_protection_active"); got != true {
+		t.Errorf("expected whois_protection_active from unmanaged extra data, got %v", got)
+	}
+	if got := d.Get("extra_data").(map[string]interface{}); len(got) != 0 {
+		t.Errorf("expected the unmanaged extra data to stay out of extra_data, got %v", got)
+	}
+}
+
+func TestResourceDomainReadRemoved(t *testing.T) {
+	stub := apitest.NewStub()
+	stub.Respond("domain.info", apitest.Error(2303, "Object does not exist"))
+
+	d := stateData(t, DomainResource(), "example.com", map[string]interface{}{"name": "example.com"})
+	requireNoErrors(t, resourceDomainRead(context.Background(), d, stub.Client(t)))
+
+	if d.Id() != "" {
+		t.Errorf("expected a domain that does not exist to be removed from state, got %q", d.Id())
+	}
+}
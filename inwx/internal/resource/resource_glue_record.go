@@ -5,7 +5,10 @@ import (
 	"fmt"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/inwx/terraform-provider-inwx/inwx/internal/api"
+	"net/netip"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -55,16 +58,39 @@ func GlueRecordResource() *schema.Resource {
 				Description: "Ip address(es)",
 				Type:        schema.TypeList,
 				Elem: &schema.Schema{
-					Type: schema.TypeString,
+					Type:         schema.TypeString,
+					ValidateFunc: validation.IsIPAddress,
 				},
 				Required: true,
 			},
+			"ipv4": {
+				Description: "IPv4 addresses of the host, sorted",
+				Type:        schema.TypeList,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Computed: true,
+			},
+			"ipv6": {
+				Description: "IPv6 addresses of the host, sorted",
+				Type:        schema.TypeList,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Computed: true,
+			},
 			"testing": {
 				Description: "Execute command in testing mode",
 				Type:        schema.TypeBool,
 				Required:    false,
 				Optional:    true,
 			},
+			"check_domain": {
+				Description: "Check before creating the glue record that the domain of the host is in the account",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
 		},
 	}
 }
@@ -75,9 +101,16 @@ func resourceGlueRecordCreate(ctx context.Context, d *schema.ResourceData, m int
 
 	hostname := d.Get("hostname").(string)
 
+	if d.Get("check_domain").(bool) {
+		checkDiags := checkGlueRecordDomain(ctx, client, hostname)
+		if checkDiags.HasError() {
+			return checkDiags
+		}
+	}
+
 	parameters := map[string]interface{}{
 		"hostname": hostname,
-		"ip":       d.Get("ip").(*schema.Set).List(),
+		"ip":       d.Get("ip").([]interface{}),
 	}
 
 	if testing, ok := d.GetOk("testing"); ok {
@@ -93,11 +126,11 @@ func resourceGlueRecordCreate(ctx context.Context, d *schema.ResourceData, m int
 		})
 		return diags
 	}
-	if call.Code() != api.COMMAND_SUCCESSFUL && call.Code() != api.COMMAND_SUCCESSFUL_PENDING {
+	if !call.IsSuccess() {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
 			Summary:  "Could not create glue host",
-			Detail:   fmt.Sprintf("API response not status code 1000 or 1001. Got response: %s", call.ApiError()),
+			Detail:   fmt.Sprintf("API response not status code 1000 or 1001. Got response: %s", call.ErrorMessage()),
 		})
 		return diags
 	}
@@ -106,7 +139,12 @@ func resourceGlueRecordCreate(ctx context.Context, d *schema.ResourceData, m int
 
 	d.SetId(hostname + ":" + strconv.Itoa(int(resData["roId"].(float64))))
 
-	resourceNameserverRecordRead(ctx, d, m)
+	// a host created in testing mode does not exist and cannot be read back
+	if client.Testing || d.Get("testing").(bool) {
+		return diags
+	}
+
+	diags = append(diags, resourceGlueRecordRead(ctx, d, m)...)
 
 	return diags
 }
@@ -128,25 +166,40 @@ func resourceGlueRecordRead(ctx context.Context, d *schema.ResourceData, m inter
 		})
 		return diags
 	}
+	if call.Code() == 2303 { // Object does not exist
+		d.SetId("")
+		return diags
+	}
 	if call.Code() != api.COMMAND_SUCCESSFUL {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
 			Summary:  "Could not get glue record info",
-			Detail:   fmt.Sprintf("API response not status code 1000. Got response: %s", call.ApiError()),
+			Detail:   fmt.Sprintf("API response not status code 1000. Got response: %s", call.ErrorMessage()),
 		})
 		return diags
 	}
 
-	records := call["resData"].(map[string]any)["record"].([]any)
+	resData, _ := call["resData"].(map[string]any)
+	records, _ := resData["record"].([]any)
 
 	for _, record := range records {
-		recordt := record.(map[string]any)
+		recordt, ok := record.(map[string]any)
+		if !ok {
+			continue
+		}
+		roId, ok := recordt["roId"].(float64)
+		if !ok {
+			continue
+		}
 
-		if d.Get("hostname").(string)+":"+strconv.Itoa(int(recordt["roId"].(float64))) == d.Id() {
-			d.Set("ro_id", d.Get("ro_id").(string))
+		if d.Get("hostname").(string)+":"+strconv.Itoa(int(roId)) == d.Id() {
 			d.Set("hostname", d.Get("hostname").(string))
-			d.Set("status", recordt["status"].(string))
-			d.Set("ip", recordt["ip"].(*schema.Set).List())
+
+			ips, _ := recordt["ip"].([]any)
+			ipv4, ipv6 := splitGlueRecordIps(ips)
+			d.Set("ip", flattenGlueRecordIps(d.Get("ip").([]interface{}), append(ipv4, ipv6...)))
+			d.Set("ipv4", ipv4)
+			d.Set("ipv6", ipv6)
 		}
 	}
 
@@ -169,17 +222,17 @@ func resourceGlueRecordUpdate(ctx context.Context, d *schema.ResourceData, m int
 
 	parameters := map[string]interface{}{
 		"roId": id,
-		"ip":   d.Get("ip").(*schema.Set).List(),
+		"ip":   d.Get("ip").([]interface{}),
 	}
 
 	if d.HasChange("hostname") {
 		parameters["hostname"] = d.Get("hostname").(string)
 	}
-	if testing, ok := d.GetOk("testing"); ok && d.HasChange("testing") {
+	if testing, ok := d.GetOk("testing"); ok {
 		parameters["testing"] = testing
 	}
 
-	err = client.CallNoResponseBody(ctx, "host.update", parameters)
+	call, err := client.Call(ctx, "host.update", parameters)
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -188,6 +241,17 @@ func resourceGlueRecordUpdate(ctx context.Context, d *schema.ResourceData, m int
 		})
 		return diags
 	}
+	if !call.IsSuccess() {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not update glue record",
+			Detail:   fmt.Sprintf("API response not status code 1000 or 1001. Got response: %s", call.ErrorMessage()),
+		})
+		return diags
+	}
+
+	// the id contains the hostname, so it changes with a renamed host
+	d.SetId(d.Get("hostname").(string) + ":" + id)
 
 	return diags
 }
@@ -229,3 +293,92 @@ func resourceGlueRecordDelete(ctx context.Context, d *schema.ResourceData, m int
 
 	return diags
 }
+
+// checkGlueRecordDomain makes sure that one of the parent domains of the host is registered in the account.
+func checkGlueRecordDomain(ctx context.Context, client *api.Client, hostname string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	labels := strings.Split(strings.TrimSuffix(hostname, "."), ".")
+	for i := 1; i < len(labels)-1; i++ {
+		domain := strings.Join(labels[i:], ".")
+
+		call, err := client.Call(ctx, "domain.info", map[string]interface{}{
+			"domain": domain,
+		})
+		if err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Could not get domain info",
+				Detail:   err.Error(),
+			})
+			return diags
+		}
+		if call.Code() == api.COMMAND_SUCCESSFUL {
+			return diags
+		}
+	}
+
+	diags = append(diags, diag.Diagnostic{
+		Severity: diag.Error,
+		Summary:  "Domain of glue record not in account",
+		Detail: fmt.Sprintf("Glue records can only be created for hosts below a domain in your account, "+
+			"but no parent domain of '%s' was found", hostname),
+	})
+	return diags
+}
+
+// splitGlueRecordIps splits the ip addresses of a host by address family. Both lists are sorted, so that the order
+// of the api response does not matter.
+func splitGlueRecordIps(ips []any) ([]string, []string) {
+	var ipv4, ipv6 []netip.Addr
+	for _, ip := range ips {
+		addr, err := netip.ParseAddr(fmt.Sprint(ip))
+		if err != nil {
+			continue
+		}
+		if addr.Is4() || addr.Is4In6() {
+			ipv4 = append(ipv4, addr.Unmap())
+		} else {
+			ipv6 = append(ipv6, addr)
+		}
+	}
+
+	format := func(addrs []netip.Addr) []string {
+		sort.Slice(addrs, func(i, j int) bool {
+			return addrs[i].Less(addrs[j])
+		})
+		result := make([]string, len(addrs))
+		for i, addr := range addrs {
+			result[i] = addr.String()
+		}
+		return result
+	}
+
+	return format(ipv4), format(ipv6)
+}
+
+// flattenGlueRecordIps keeps the configured order of ip if it contains the same addresses as the host, otherwise the
+// sorted addresses of the host are returned.
+func flattenGlueRecordIps(configured []interface{}, ips []string) []string {
+	if len(configured) != len(ips) {
+		return ips
+	}
+
+	remaining := map[netip.Addr]int{}
+	for _, ip := range ips {
+		addr, _ := netip.ParseAddr(ip)
+		remaining[addr]++
+	}
+
+	result := make([]string, len(configured))
+	for i, ip := range configured {
+		addr, err := netip.ParseAddr(fmt.Sprint(ip))
+		if err != nil || remaining[addr.Unmap()] == 0 {
+			return ips
+		}
+		remaining[addr.Unmap()]--
+		result[i] = fmt.Sprint(ip)
+	}
+
+	return result
+}
@@ -0,0 +1,182 @@
+package resource
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/inwx/terraform-provider-inwx/inwx/internal/api/apitest"
+)
+
+// hostInfo returns a host.info response of ns1.example.com with roId and ips.
+func hostInfo(roId int, ips ...interface{}) map[string]interface{} {
+	return apitest.Success(map[string]interface{}{
+		"record": []interface{}{map[string]interface{}{
+			"hostname": "ns1.example.com",
+			"roId":     roId,
+			"status":   "OK",
+			"ip":       ips,
+		}},
+	})
+}
+
+func TestRoIdSchemasAreInts(t *testing.T) {
+	for name, r := range map[string]*schema.Resource{
+		"inwx_domain":            DomainResource(),
+		"inwx_glue_record":       GlueRecordResource(),
+		"inwx_nameserver_record": NameserverRecordResource(),
+	} {
+		if got := r.Schema["ro_id"].Type; got != schema.TypeInt {
+			t.Errorf("expected ro_id of %s to be %s, got %s", name, schema.TypeInt, got)
+		}
+	}
+}
+
+func TestResourceGlueRecordCreate(t *testing.T) {
+	stub := apitest.NewStub()
+	stub.Respond("host.create", apitest.Success(map[string]interface{}{"roId": 77}))
+	stub.Respond("host.info", hostInfo(77, "2001:db8::1", "192.0.2.1"))
+	client := stub.Client(t)
+
+	d := planData(t, GlueRecordResource(), "", nil, map[string]interface{}{
+		"hostname": "ns1.example.com",
+		"ro_id":    77,
+		"ip":       []interface{}{"192.0.2.1", "2001:db8::1"},
+	}, client)
+	requireNoErrors(t, resourceGlueRecordCreate(context.Background(), d, client))
+
+	if d.Id() != "ns1.example.com:77" {
+		t.Errorf("expected id ns1.example.com:77, got %s", d.Id())
+	}
+	if d.Get("ro_id") != 77 {
+		t.Errorf("expected ro_id 77 after create, got %v", d.Get("ro_id"))
+	}
+	if ipv4 := d.Get("ipv4").([]interface{}); len(ipv4) != 1 || ipv4[0] != "192.0.2.1" {
+		t.Errorf("unexpected ipv4 %v", ipv4)
+	}
+}
+
+func TestResourceGlueRecordCreateCheckDomain(t *testing.T) {
+	config := map[string]interface{}{
+		"hostname":     "ns1.sub.example.com",
+		"ip":           []interface{}{"192.0.2.1"},
+		"check_domain": true,
+	}
+
+	t.Run("domain in account", func(t *testing.T) {
+		stub := apitest.NewStub()
+		stub.Handle("domain.info", func(params map[string]interface{}) map[string]interface{} {
+			if params["domain"] == "example.com" {
+				return domainInfo(nil)
+			}
+			return apitest.Error(2303, "Object does not exist")
+		})
+		stub.Respond("host.create", apitest.Success(map[string]interface{}{"roId": 77}))
+		stub.Respond("host.info", hostInfo(77, "192.0.2.1"))
+		client := stub.Client(t)
+
+		d := planData(t, GlueRecordResource(), "", nil, config, client)
+		requireNoErrors(t, resourceGlueRecordCreate(context.Background(), d, client))
+
+		var domains []interface{}
+		for _, call := range stub.Calls("domain.info") {
+			domains = append(domains, call.Params["domain"])
+		}
+		if len(domains) != 2 || domains[0] != "sub.example.com" || domains[1] != "example.com" {
+			t.Errorf("expected the parent domains to be checked from the host up, got %v", domains)
+		}
+		if len(stub.Calls("host.create")) != 1 {
+			t.Error("expected the glue record to be created")
+		}
+	})
+
+	t.Run("domain not in account", func(t *testing.T) {
+		stub := apitest.NewStub()
+		stub.Respond("domain.info", apitest.Error(2303, "Object does not exist"))
+		client := stub.Client(t)
+
+		d := planData(t, GlueRecordResource(), "", nil, config, client)
+		diags := resourceGlueRecordCreate(context.Background(), d, client)
+		if !diags.HasError() || diags[0].Summary != "Domain of glue record not in account" {
+			t.Fatalf("expected a domain not in account error, got %v", diags)
+		}
+		if calls := stub.Calls("host.create"); len(calls) != 0 {
+			t.Errorf("expected no host.create, got %v", calls)
+		}
+	})
+}
+
+func TestResourceGlueRecordUpdate(t *testing.T) {
+	stub := apitest.NewStub()
+	stub.Respond("host.update", apitest.Success(nil))
+	client := stub.Client(t)
+
+	state := map[string]interface{}{
+		"hostname": "ns1.example.com",
+		"ro_id":    77,
+		"ip":       []interface{}{"192.0.2.1"},
+		"testing":  true,
+	}
+	config := map[string]interface{}{
+		"hostname": "ns2.example.com",
+		"ro_id":    77,
+		"ip":       []interface{}{"192.0.2.2"},
+		"testing":  true,
+	}
+	d := planData(t, GlueRecordResource(), "ns1.example.com:77", state, config, client)
+	requireNoErrors(t, resourceGlueRecordUpdate(context.Background(), d, client))
+
+	params := stub.LastCall("host.update")
+	if params["roId"] != "77" || params["hostname"] != "ns2.example.com" || params["testing"] != true {
+		t.Errorf("unexpected host.update parameters %v", params)
+	}
+	if ips, _ := params["ip"].([]interface{}); len(ips) != 1 || ips[0] != "192.0.2.2" {
+		t.Errorf("expected the new ip, got %v", params["ip"])
+	}
+	if d.Id() != "ns2.example.com:77" {
+		t.Errorf("expected the id to follow the hostname, got %s", d.Id())
+	}
+}
+
+func TestResourceGlueRecordUpdateApiError(t *testing.T) {
+	stub := apitest.NewStub()
+	stub.Respond("host.update", apitest.Error(2303, "Object does not exist"))
+	client := stub.Client(t)
+
+	state := map[string]interface{}{"hostname": "ns1.example.com", "ro_id": 77, "ip": []interface{}{"192.0.2.1"}}
+	config := map[string]interface{}{"hostname": "ns2.example.com", "ro_id": 77, "ip": []interface{}{"192.0.2.1"}}
+	d := planData(t, GlueRecordResource(), "ns1.example.com:77", state, config, client)
+	if diags := resourceGlueRecordUpdate(context.Background(), d, client); !diags.HasError() {
+		t.Fatal("expected error")
+	}
+	if d.Id() != "ns1.example.com:77" {
+		t.Errorf("expected the id to be kept after a failed update, got %s", d.Id())
+	}
+}
+
+func TestResourceGlueRecordCreateProviderTesting(t *testing.T) {
+	stub := apitest.NewStub()
+	stub.Respond("host.create", apitest.Success(map[string]interface{}{"roId": 77}))
+	stub.Respond("host.info", apitest.Error(2303, "Object does not exist"))
+	client := stub.Client(t)
+	client.Testing = true
+
+	d := planData(t, GlueRecordResource(), "", nil, map[string]interface{}{
+		"hostname": "ns1.example.com",
+		"ro_id":    77,
+		"ip":       []interface{}{"192.0.2.1"},
+	}, client)
+	requireNoErrors(t, resourceGlueRecordCreate(context.Background(), d, client))
+
+	if got := stub.LastCall("host.create")["testing"]; got != true {
+		t.Errorf("expected the host to be created in testing mode, got %v", got)
+	}
+	if d.Id() != "ns1.example.com:77" {
+		t.Errorf("expected the id to be kept after create in testing mode, got %q", d.Id())
+	}
+
+	requireNoErrors(t, resourceGlueRecordRead(context.Background(), d, client))
+	if d.Id() != "" {
+		t.Errorf("expected the host to be removed from state on refresh, got %q", d.Id())
+	}
+}
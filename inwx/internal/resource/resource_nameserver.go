@@ -6,11 +6,23 @@ import (
 	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/inwx/terraform-provider-inwx/inwx/internal/api"
 	"strconv"
 	"strings"
 )
 
+// dnssecRecordTypes are created and signed by INWX for zones with DNSSEC and cannot be managed as records.
+var dnssecRecordTypes = map[string]bool{
+	"DNSKEY":     true,
+	"RRSIG":      true,
+	"NSEC":       true,
+	"NSEC3":      true,
+	"NSEC3PARAM": true,
+	"CDS":        true,
+	"CDNSKEY":    true,
+}
+
 func resourceNameserverParseId(id string) (string, string, error) {
 	parts := strings.Split(id, ":")
 
@@ -33,7 +45,9 @@ func NameserverResource() *schema.Resource {
 	return &schema.Resource{
 		CreateContext: resourceNameserverCreate,
 		ReadContext:   resourceNameserverRead,
+		UpdateContext: resourceNameserverUpdate,
 		DeleteContext: resourceNameserverDelete,
+		CustomizeDiff: resourceNameserverCustomizeDiff,
 		Importer: &schema.ResourceImporter{
 			StateContext: func(ctx context.Context, d *schema.ResourceData, i interface{}) ([]*schema.ResourceData, error) {
 				domain, id, err := resourceNameserverParseId(d.Id())
@@ -90,10 +104,10 @@ func NameserverResource() *schema.Resource {
 				ForceNew: true,
 			},
 			"master_ip": {
-				Description: "Master IP address",
-				Type:        schema.TypeString,
-				Optional:    true,
-				ForceNew:    true,
+				Description:  "Master IP address. Required for SLAVE zones",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.IsIPAddress,
 			},
 			"web": {
 				Description: "Web nameserver entry",
@@ -111,8 +125,42 @@ func NameserverResource() *schema.Resource {
 				Description: "Email address for SOA record",
 				Type:        schema.TypeString,
 				Optional:    true,
+				Computed:    true,
 				ForceNew:    true,
 			},
+			"soa_refresh": {
+				Description:  "Refresh interval of the SOA record in seconds",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Computed:     true,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"soa_retry": {
+				Description:  "Retry interval of the SOA record in seconds",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Computed:     true,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"soa_expire": {
+				Description:  "Expire time of the SOA record in seconds",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Computed:     true,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"soa_ttl": {
+				Description:  "Minimum TTL of the SOA record in seconds, used for negative caching",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Computed:     true,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"soa_serial": {
+				Description: "Serial of the SOA record. INWX increments the serial on every change of the zone",
+				Type:        schema.TypeInt,
+				Computed:    true,
+			},
 			"url_redirect_type": {
 				Description: "Type of the url redirection. One of: " + strings.Join(validUrlRedirectTypes, ", "),
 				Type:        schema.TypeString,
@@ -133,31 +181,26 @@ func NameserverResource() *schema.Resource {
 					})
 					return diags
 				},
-				ForceNew: true,
 			},
 			"url_redirect_title": {
 				Description: "Title of the frame redirection",
 				Type:        schema.TypeString,
 				Optional:    true,
-				ForceNew:    true,
 			},
 			"url_redirect_description": {
 				Description: "Description of the frame redirection",
 				Type:        schema.TypeString,
 				Optional:    true,
-				ForceNew:    true,
 			},
 			"url_redirect_fav_icon": {
 				Description: "FavIcon of the frame redirection",
 				Type:        schema.TypeString,
 				Optional:    true,
-				ForceNew:    true,
 			},
 			"url_redirect_keywords": {
 				Description: "Keywords of the frame redirection",
 				Type:        schema.TypeString,
 				Optional:    true,
-				ForceNew:    true,
 			},
 			"testing": {
 				Description: "Execute command in testing mode",
@@ -172,6 +215,20 @@ func NameserverResource() *schema.Resource {
 				Optional:    true,
 				ForceNew:    true,
 			},
+			"warn_unmanaged_records": {
+				Description: "Emit a warning on read for records in the zone that are not listed in managed_record_ids",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			"managed_record_ids": {
+				Description: "Ids of the records in the zone that are managed by terraform, e.g. the ids of inwx_nameserver_record resources",
+				Type:        schema.TypeSet,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Optional: true,
+			},
 		},
 	}
 }
@@ -199,6 +256,9 @@ func resourceNameserverCreate(ctx context.Context, d *schema.ResourceData, m int
 	if mail, ok := d.GetOk("mail"); ok {
 		parameters["mail"] = mail
 	}
+	if soaMail, ok := d.GetOk("soa_mail"); ok {
+		parameters["soaEmail"] = soaMail
+	}
 	if urlRedirectType, ok := d.GetOk("url_redirect_type"); ok {
 		parameters["urlRedirectType"] = urlRedirectType
 	}
@@ -230,34 +290,64 @@ func resourceNameserverCreate(ctx context.Context, d *schema.ResourceData, m int
 		})
 		return diags
 	}
-	if call.Code() != api.COMMAND_SUCCESSFUL && call.Code() != api.COMMAND_SUCCESSFUL_PENDING {
+	if !call.IsSuccess() {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
 			Summary:  "Could not add nameserver record",
-			Detail:   fmt.Sprintf("API response not status code 1000 or 1001. Got response: %s", call.ApiError()),
+			Detail:   fmt.Sprintf("API response not status code 1000 or 1001. Got response: %s", call.ErrorMessage()),
 		})
 		return diags
 	}
 
+	client.InvalidateNameserverInfo(domain)
+	// records of the zone created in the same run use its testing mode, see nameserverRecordTesting
+	client.SetTestingZone(domain, d.Get("testing").(bool))
+
 	resData := call["resData"].(map[string]any)
 
 	d.SetId(domain + ":" + strconv.Itoa(int(resData["roId"].(float64))))
 
-	resourceNameserverRecordRead(ctx, d, m)
+	// a zone created in testing mode does not exist, so its SOA cannot be updated and it cannot be read back
+	if client.Testing || d.Get("testing").(bool) {
+		return diags
+	}
 
-	return diags
+	for _, field := range soaTimerFields {
+		if _, ok := d.GetOk(field); ok {
+			diags = append(diags, updateNameserverSoa(ctx, d, client)...)
+			if diags.HasError() {
+				return diags
+			}
+			break
+		}
+	}
+
+	return append(diags, resourceNameserverRead(ctx, d, m)...)
 }
 
 func resourceNameserverRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 	client := m.(*api.Client)
 
-	parameters := map[string]interface{}{
-		"domain": d.Get("domain"),
-	}
-
-	call, err := client.Call(ctx, "nameserver.info", parameters)
+	call, err := client.NameserverInfo(ctx, d.Get("domain").(string))
 	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not get nameserver info",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	if call.Code() == 2303 { // Object does not exist
+		d.SetId("")
+		return diags
+	}
+	if call.Code() != api.COMMAND_SUCCESSFUL {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not get nameserver info",
+			Detail:   fmt.Sprintf("API response not status code 1000. Got response: %s", call.ErrorMessage()),
+		})
 		return diags
 	}
 
@@ -286,11 +376,376 @@ func resourceNameserverRead(ctx context.Context, d *schema.ResourceData, m inter
 				return diags
 			}
 		}
+
+		// MASTER zones have no master ip, an empty value keeps imported zones consistent with the configuration
+		masterIp, _ := resData["masterIp"].(string)
+		d.Set("master_ip", masterIp)
+
+		if records, ok := resData["record"].([]any); ok {
+			domain := d.Get("domain").(string)
+
+			for _, record := range records {
+				recordt, ok := record.(map[string]any)
+				if !ok {
+					continue
+				}
+				name, _ := recordt["name"].(string)
+				if recordt["type"] != "URL" || !isApexRecordName(name, domain) {
+					continue
+				}
+
+				// the url_redirect_* fields of a zone describe the URL record created for the web entry
+				if val, ok := recordt["urlRedirectType"]; ok {
+					d.Set("url_redirect_type", val.(string))
+				}
+				if val, ok := recordt["urlRedirectTitle"]; ok {
+					d.Set("url_redirect_title", val.(string))
+				}
+				if val, ok := recordt["urlRedirectDescription"]; ok {
+					d.Set("url_redirect_description", val.(string))
+				}
+				if val, ok := recordt["urlRedirectKeywords"]; ok {
+					d.Set("url_redirect_keywords", val.(string))
+				}
+				if val, ok := recordt["urlRedirectFavIcon"]; ok {
+					d.Set("url_redirect_fav_icon", val.(string))
+				}
+			}
+
+			for _, record := range records {
+				recordt, ok := record.(map[string]any)
+				if !ok || recordt["type"] != "SOA" {
+					continue
+				}
+				// SOA content: <mname> <rname> <serial> <refresh> <retry> <expire> <minimum>
+				parts := strings.Fields(recordt["content"].(string))
+				if len(parts) >= 2 {
+					d.Set("soa_mail", transformRname(parts[1]))
+				}
+				if len(parts) >= 3 {
+					if val, err := strconv.Atoi(parts[2]); err == nil {
+						d.Set("soa_serial", val)
+					}
+				}
+				for i, field := range soaTimerFields {
+					if len(parts) < i+4 {
+						break
+					}
+					if val, err := strconv.Atoi(parts[i+3]); err == nil {
+						d.Set(field, val)
+					}
+				}
+			}
+
+			d.Set("nameservers", apexNameservers(d.Get("nameservers").([]interface{}), records, domain))
+
+			if d.Get("warn_unmanaged_records").(bool) {
+				diags = append(diags, unmanagedNameserverRecordsWarning(d, records)...)
+			}
+		}
 	}
 
 	return diags
 }
 
+func resourceNameserverCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !d.NewValueKnown("type") || !d.NewValueKnown("master_ip") {
+		return nil
+	}
+
+	_, hasMasterIp := d.GetOk("master_ip")
+
+	switch d.Get("type").(string) {
+	case "SLAVE":
+		if !hasMasterIp {
+			return fmt.Errorf("master_ip is required for SLAVE zones")
+		}
+	case "MASTER":
+		if hasMasterIp {
+			return fmt.Errorf("master_ip can only be set for SLAVE zones")
+		}
+	}
+
+	return nil
+}
+
+// nameserverUpdateFields maps the attributes that can be changed with nameserver.update to the parameters of the api.
+var nameserverUpdateFields = map[string]string{
+	"master_ip":                "masterIp",
+	"url_redirect_type":        "urlRedirectType",
+	"url_redirect_title":       "urlRedirectTitle",
+	"url_redirect_description": "urlRedirectDescription",
+	"url_redirect_fav_icon":    "urlRedirectFavIcon",
+	"url_redirect_keywords":    "urlRedirectKeywords",
+}
+
+// resourceNameserverUpdate applies changed zone settings with nameserver.update and changed SOA timers to the SOA
+// record. The records of the zone are not touched, all other attributes force a new zone.
+func resourceNameserverUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	client := m.(*api.Client)
+
+	domain := d.Get("domain").(string)
+
+	parameters := map[string]interface{}{
+		"domain": domain,
+	}
+	changed := false
+	for attribute, parameter := range nameserverUpdateFields {
+		// removed values are sent as empty string to remove them from the zone
+		if d.HasChange(attribute) {
+			parameters[parameter] = d.Get(attribute).(string)
+			changed = true
+		}
+	}
+	if testing, ok := d.GetOk("testing"); ok {
+		parameters["testing"] = testing
+	}
+
+	if changed {
+		call, err := client.Call(ctx, "nameserver.update", parameters)
+		client.InvalidateNameserverInfo(domain)
+		if err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Could not update nameserver",
+				Detail:   err.Error(),
+			})
+			return diags
+		}
+		if !call.IsSuccess() {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Could not update nameserver",
+				Detail:   fmt.Sprintf("API response not status code 1000 or 1001. Got response: %s", call.ErrorMessage()),
+			})
+			return diags
+		}
+	}
+
+	if d.HasChanges(soaTimerFields...) {
+		diags = append(diags, updateNameserverSoa(ctx, d, client)...)
+		if diags.HasError() {
+			return diags
+		}
+	}
+
+	if client.Testing || d.Get("testing").(bool) {
+		return diags
+	}
+
+	return append(diags, resourceNameserverRead(ctx, d, m)...)
+}
+
+// soaTimerFields are the configurable fields of the SOA record content in the order they appear after mname, rname
+// and the serial, which is maintained by INWX.
+var soaTimerFields = []string{"soa_refresh", "soa_retry", "soa_expire", "soa_ttl"}
+
+// updateNameserverSoa writes the configured SOA timers into the SOA record of the zone. Fields that are not
+// configured keep the value of the current SOA record.
+func updateNameserverSoa(ctx context.Context, d *schema.ResourceData, client *api.Client) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	domain := d.Get("domain").(string)
+
+	client.InvalidateNameserverInfo(domain)
+	info, err := client.NameserverInfo(ctx, domain)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not get nameserver info",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	resData, _ := info["resData"].(map[string]any)
+	records, _ := resData["record"].([]any)
+
+	for _, record := range records {
+		recordt, ok := record.(map[string]any)
+		if !ok || recordt["type"] != "SOA" {
+			continue
+		}
+		id, ok := recordt["id"].(float64)
+		if !ok {
+			continue
+		}
+		content, _ := recordt["content"].(string)
+		parts := strings.Fields(content)
+		if len(parts) != 7 {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Could not update SOA record",
+				Detail:   fmt.Sprintf("Unexpected content of the SOA record of %s: %s", domain, content),
+			})
+			return diags
+		}
+
+		for i, field := range soaTimerFields {
+			if val, ok := d.GetOk(field); ok {
+				parts[i+3] = strconv.Itoa(val.(int))
+			}
+		}
+
+		parameters := map[string]interface{}{
+			"id":      int(id),
+			"content": strings.Join(parts, " "),
+		}
+		if testing, ok := d.GetOk("testing"); ok {
+			parameters["testing"] = testing
+		}
+
+		call, err := client.Call(ctx, "nameserver.updateRecord", parameters)
+		client.InvalidateNameserverInfo(domain)
+		if err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Could not update SOA record",
+				Detail:   err.Error(),
+			})
+			return diags
+		}
+		if !call.IsSuccess() {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Could not update SOA record",
+				Detail:   fmt.Sprintf("API response not status code 1000 or 1001. Got response: %s", call.ErrorMessage()),
+			})
+		}
+		return diags
+	}
+
+	diags = append(diags, diag.Diagnostic{
+		Severity: diag.Error,
+		Summary:  "Could not update SOA record",
+		Detail:   fmt.Sprintf("The zone %s has no SOA record", domain),
+	})
+	return diags
+}
+
+// unmanagedNameserverRecordsWarning returns a warning listing all records of the zone which are neither part of the
+// zone itself (SOA, apex NS and DNSSEC records) nor listed in managed_record_ids.
+func unmanagedNameserverRecordsWarning(d *schema.ResourceData, records []any) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	domain := d.Get("domain").(string)
+
+	managedIds := map[string]bool{}
+	for _, managedId := range d.Get("managed_record_ids").(*schema.Set).List() {
+		// accept plain record ids as well as inwx_nameserver_record ids (domain:id)
+		parts := strings.Split(managedId.(string), ":")
+		managedIds[parts[len(parts)-1]] = true
+	}
+
+	var unmanaged []string
+	for _, record := range records {
+		recordt, ok := record.(map[string]any)
+		if !ok {
+			continue
+		}
+		recordType, _ := recordt["type"].(string)
+		name, _ := recordt["name"].(string)
+		if recordType == "SOA" || (recordType == "NS" && isApexRecordName(name, domain)) || dnssecRecordTypes[recordType] {
+			continue
+		}
+		id, ok := recordt["id"].(float64)
+		if ok && managedIds[strconv.Itoa(int(id))] {
+			continue
+		}
+
+		content, _ := recordt["content"].(string)
+		unmanaged = append(unmanaged, fmt.Sprintf("%s %s %s", name, recordType, content))
+	}
+
+	if len(unmanaged) > 0 {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  "Zone contains unmanaged records",
+			Detail: fmt.Sprintf("The zone %s contains %d records that are not listed in managed_record_ids:\n%s",
+				domain, len(unmanaged), strings.Join(unmanaged, "\n")),
+		})
+	}
+
+	return diags
+}
+
+// apexNameservers returns the content of all NS records at the zone apex. NS records of delegated subzones
+// are not nameservers of the zone. If the nameservers equal the current ones, the current order is kept.
+func apexNameservers(current []interface{}, records []any, domain string) []interface{} {
+	var nameservers []interface{}
+
+	for _, record := range records {
+		recordt, ok := record.(map[string]any)
+		if !ok || recordt["type"] != "NS" {
+			continue
+		}
+		name, _ := recordt["name"].(string)
+		content, _ := recordt["content"].(string)
+		if !isApexRecordName(name, domain) || content == "" {
+			continue
+		}
+
+		nameservers = append(nameservers, content)
+	}
+
+	return flattenNameservers(current, nameservers)
+}
+
+// flattenNameservers returns the current nameservers if they equal the nameservers returned by the api apart from
+// order, case and trailing dots, so that the api does not produce diffs by returning them in another order.
+// Otherwise the nameservers of the api are returned.
+func flattenNameservers(current []interface{}, nameservers []interface{}) []interface{} {
+	serverNameservers := map[string]bool{}
+	for _, nameserver := range nameservers {
+		serverNameservers[normalizeHostname(fmt.Sprint(nameserver))] = true
+	}
+
+	if len(current) != len(serverNameservers) {
+		return nameservers
+	}
+	for _, nameserver := range current {
+		if !serverNameservers[normalizeHostname(nameserver.(string))] {
+			return nameservers
+		}
+	}
+
+	return current
+}
+
+// transformRname converts the rname of a SOA record to an email address. The first unescaped dot separates the
+// local part from the domain, dots in the local part are escaped, e.g. first\.last.example.com is first.last@example.com.
+func transformRname(rname string) string {
+	rname = strings.TrimSuffix(rname, ".")
+
+	var localPart strings.Builder
+	for i := 0; i < len(rname); i++ {
+		switch {
+		case rname[i] == '\\' && i+1 < len(rname):
+			i++
+			localPart.WriteByte(rname[i])
+		case rname[i] == '.':
+			return localPart.String() + "@" + rname[i+1:]
+		default:
+			localPart.WriteByte(rname[i])
+		}
+	}
+
+	// not an email address in rname format, keep it as it is
+	return rname
+}
+
+// normalizeHostname lowercases a hostname and removes the trailing dot of fully qualified names.
+func normalizeHostname(hostname string) string {
+	return strings.TrimSuffix(strings.ToLower(hostname), ".")
+}
+
+// isApexRecordName reports whether a record name returned by nameserver.info refers to the zone apex.
+func isApexRecordName(name string, domain string) bool {
+	name = normalizeHostname(name)
+	return name == "" || name == "@" || name == normalizeHostname(domain)
+}
+
 func resourceNameserverDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 	client := m.(*api.Client)
@@ -304,6 +759,7 @@ func resourceNameserverDelete(ctx context.Context, d *schema.ResourceData, m int
 	}
 
 	err := client.CallNoResponseBody(ctx, "nameserver.delete", parameters)
+	client.InvalidateNameserverInfo(d.Get("domain").(string))
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
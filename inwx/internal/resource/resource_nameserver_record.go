@@ -8,10 +8,43 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/inwx/terraform-provider-inwx/inwx/internal/api"
+	"net"
+	"regexp"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
+// recordNameRegexp matches relative and fully qualified record names. Labels may contain underscores, e.g. _dmarc,
+// and the first label may be a wildcard, e.g. *._domainkey.
+var recordNameRegexp = regexp.MustCompile(`^(@|(\*|[\p{L}\p{N}_]([\p{L}\p{N}_-]*[\p{L}\p{N}_])?)(\.[\p{L}\p{N}_]([\p{L}\p{N}_-]*[\p{L}\p{N}_])?)*\.?)$`)
+
+// hostnameRegexp matches relative and fully qualified hostnames in the content of records, e.g. the target of a CNAME.
+var hostnameRegexp = regexp.MustCompile(`^[\p{L}\p{N}_]([\p{L}\p{N}_-]*[\p{L}\p{N}_])?(\.[\p{L}\p{N}_]([\p{L}\p{N}_-]*[\p{L}\p{N}_])?)*\.?$`)
+
+var caaContentRegexp = regexp.MustCompile(`^(\d{1,3})\s+(issue|issuewild|iodef)\s+"(.*)"$`)
+
+var txtSegmentsRegexp = regexp.MustCompile(`^"(?:[^"\\]|\\.)*"(?:\s+"(?:[^"\\]|\\.)*")+$`)
+var txtSegmentRegexp = regexp.MustCompile(`"((?:[^"\\]|\\.)*)"`)
+var txtEscapeRegexp = regexp.MustCompile(`\\(.)`)
+
+// txtSegmentLength is the maximum length of a single character-string in a TXT record
+const txtSegmentLength = 255
+
+// prioRecordTypes are the record types which have a priority. prio is not sent for other types.
+var prioRecordTypes = map[string]bool{
+	"MX":  true,
+	"SRV": true,
+	"URI": true,
+}
+
+// recordTypeConflicts lists the record types which cannot exist at the same name as the key type.
+// CNAME records conflict with every other type.
+var recordTypeConflicts = map[string][]string{
+	"URL":   {"A", "AAAA", "ALIAS", "CNAME"},
+	"ALIAS": {"A", "AAAA", "URL", "CNAME"},
+}
+
 func resourceNameserverRecordParseId(id string) (string, string, error) {
 	parts := strings.Split(id, ":")
 
@@ -37,6 +70,7 @@ func NameserverRecordResource() *schema.Resource {
 		ReadContext:   resourceNameserverRecordRead,
 		UpdateContext: resourceNameserverRecordUpdate,
 		DeleteContext: resourceNameserverRecordDelete,
+		CustomizeDiff: resourceNameserverRecordCustomizeDiff,
 		Importer: &schema.ResourceImporter{
 			StateContext: func(ctx context.Context, d *schema.ResourceData, i interface{}) ([]*schema.ResourceData, error) {
 				domain, id, err := resourceNameserverRecordParseId(d.Id())
@@ -58,14 +92,16 @@ func NameserverRecordResource() *schema.Resource {
 				Required:    true,
 			},
 			"ro_id": {
-				Description: "DNS domain id",
+				Description: "DNS domain id. Required if the account has multiple zones for the domain",
 				Type:        schema.TypeInt,
 				Optional:    true,
+				ForceNew:    true,
 			},
 			"type": {
 				Description: "Type of the nameserver record. One of: " + strings.Join(validRecordTypes, ", "),
 				Type:        schema.TypeString,
 				Required:    true,
+				ForceNew:    true,
 				ValidateDiagFunc: func(i interface{}, path cty.Path) diag.Diagnostics {
 					var diags diag.Diagnostics
 					for _, validRecordType := range validRecordTypes {
@@ -84,28 +120,54 @@ func NameserverRecordResource() *schema.Resource {
 				},
 			},
 			"content": {
-				Description: "Content of the nameserver record",
-				Type:        schema.TypeString,
-				Required:    true,
+				Description: "Content of the nameserver record. For SRV records with `port` set, only the target host. " +
+					"For CAA records with `caa_tag` set, only the value",
+				Type:     schema.TypeString,
+				Required: true,
 			},
 			"name": {
-				Description: "Name of the nameserver record",
-				Type:        schema.TypeString,
-				Optional:    true,
+				Description:  "Name of the nameserver record",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringMatch(recordNameRegexp, "must be a record name like www, _dmarc or *._domainkey"),
 			},
 			"ttl": {
-				Description:  "TTL (time to live) of the nameserver record",
+				Description:  "TTL (time to live) of the nameserver record. Defaults to default_ttl of the provider",
 				ValidateFunc: validation.IntAtLeast(300),
 				Type:         schema.TypeInt,
 				Optional:     true,
-				Default:      3600,
+				Computed:     true,
 			},
 			"prio": {
-				Description: "Priority of the nameserver record",
+				Description: "Priority of the nameserver record. Only valid for MX, SRV and URI records",
 				Type:        schema.TypeInt,
 				Optional:    true,
 				Default:     0,
 			},
+			"weight": {
+				Description:  "Weight of the SRV record",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntBetween(0, 65535),
+			},
+			"port": {
+				Description:  "Port of the SRV record. When set, `content` is the target host of the SRV record",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntBetween(1, 65535),
+			},
+			"caa_flags": {
+				Description:  "Flags of the CAA record",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntBetween(0, 255),
+			},
+			"caa_tag": {
+				Description:  "Tag of the CAA record. One of: issue, issuewild, iodef. When set, `content` is the value of the CAA record",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice([]string{"issue", "issuewild", "iodef"}, false),
+			},
 			"url_redirect_type": {
 				Description: "Type of the url redirection. One of: " + strings.Join(validUrlRedirectTypes, ", "),
 				Type:        schema.TypeString,
@@ -159,6 +221,12 @@ func NameserverRecordResource() *schema.Resource {
 				Required:    false,
 				Optional:    true,
 			},
+			"fail_on_duplicate": {
+				Description: "Fail on create if the zone already contains a record with the same name, type and content",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
 		},
 	}
 }
@@ -172,19 +240,27 @@ func resourceNameserverRecordCreate(ctx context.Context, d *schema.ResourceData,
 	parameters := map[string]interface{}{
 		"domain":  domain,
 		"type":    d.Get("type").(string),
-		"content": d.Get("content").(string),
+		"content": expandNameserverRecordContent(d),
 	}
 
 	if roId, ok := d.GetOk("ro_id"); ok {
 		parameters["roId"] = roId
+	} else {
+		diags = checkNameserverZoneAmbiguity(ctx, client, domain)
+		if diags.HasError() {
+			return diags
+		}
 	}
 	if name, ok := d.GetOk("name"); ok {
 		parameters["name"] = name
 	}
 	if ttl, ok := d.GetOk("ttl"); ok {
 		parameters["ttl"] = ttl
+	} else {
+		parameters["ttl"] = client.DefaultTTL
+		d.Set("ttl", client.DefaultTTL)
 	}
-	if prio, ok := d.GetOk("prio"); ok {
+	if prio, ok := d.GetOk("prio"); ok && prioRecordTypes[d.Get("type").(string)] {
 		parameters["prio"] = prio
 	}
 	if urlRedirectType, ok := d.GetOk("url_redirect_type"); ok {
@@ -205,12 +281,36 @@ func resourceNameserverRecordCreate(ctx context.Context, d *schema.ResourceData,
 	if urlAppend, ok := d.GetOk("url_append"); ok {
 		parameters["urlAppend"] = urlAppend
 	}
-	if testing, ok := d.GetOk("testing"); ok {
-		parameters["testing"] = testing
+	if nameserverRecordTesting(d, client) {
+		parameters["testing"] = true
+	}
+
+	if d.Get("fail_on_duplicate").(bool) {
+		diags = append(diags, checkDuplicateNameserverRecord(ctx, client, d, domain)...)
+		if diags.HasError() {
+			return diags
+		}
+	}
+
+	// the records of the zone are checked for conflicts when planning, records created in the same apply are not
+	// known then and checked here
+	record := api.ReservedRecord{Name: plannedRecordKey(domain, d.Get("name").(string)), Type: d.Get("type").(string)}
+	conflicting, ok := client.ReserveRecord(normalizeHostname(domain), record, func(other api.ReservedRecord) bool {
+		return other.Name == record.Name && recordTypesConflict(record.Type, other.Type)
+	})
+	if !ok {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Conflicting nameserver record",
+			Detail: fmt.Sprintf("A %s record cannot exist together with the %s record at %s, which is created in the same apply",
+				record.Type, conflicting.Type, record.Name),
+		})
+		return diags
 	}
 
 	call, err := client.Call(ctx, "nameserver.createRecord", parameters)
 	if err != nil {
+		client.ReleaseRecord(normalizeHostname(domain), record)
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
 			Summary:  "Could not add nameserver record",
@@ -218,18 +318,29 @@ func resourceNameserverRecordCreate(ctx context.Context, d *schema.ResourceData,
 		})
 		return diags
 	}
-	if call.Code() != api.COMMAND_SUCCESSFUL && call.Code() != api.COMMAND_SUCCESSFUL_PENDING {
+	if !call.IsSuccess() {
+		client.ReleaseRecord(normalizeHostname(domain), record)
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
 			Summary:  "Could not add nameserver record",
-			Detail:   fmt.Sprintf("API response not status code 1000 or 1001. Got response: %s", call.ApiError()),
+			Detail:   fmt.Sprintf("API response not status code 1000 or 1001. Got response: %s", call.ErrorMessage()),
 		})
 		return diags
 	}
 
-	resData := call["resData"].(map[string]any)
+	client.InvalidateNameserverInfo(domain)
 
-	d.SetId(domain + ":" + strconv.Itoa(int(resData["id"].(float64))))
+	resData, _ := call["resData"].(map[string]any)
+	id, ok := resData["id"].(float64)
+	if !ok {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not add nameserver record",
+			Detail:   fmt.Sprintf("API response contains no id. Got response: %s", call.ApiError()),
+		})
+		return diags
+	}
+	d.SetId(domain + ":" + strconv.Itoa(int(id)))
 
 	resourceNameserverRecordRead(ctx, d, m)
 
@@ -240,11 +351,7 @@ func resourceNameserverRecordRead(ctx context.Context, d *schema.ResourceData, m
 	var diags diag.Diagnostics
 	client := m.(*api.Client)
 
-	parameters := map[string]interface{}{
-		"domain": d.Get("domain"),
-	}
-
-	call, err := client.Call(ctx, "nameserver.info", parameters)
+	call, err := client.NameserverInfo(ctx, d.Get("domain").(string))
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -257,59 +364,68 @@ func resourceNameserverRecordRead(ctx context.Context, d *schema.ResourceData, m
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
 			Summary:  "Could not get nameserver info",
-			Detail:   fmt.Sprintf("API response not status code 1000. Got response: %s", call.ApiError()),
+			Detail:   fmt.Sprintf("API response not status code 1000. Got response: %s", call.ErrorMessage()),
 		})
 		return diags
 	}
 
-	records := call["resData"].(map[string]any)["record"].([]any)
+	resData, _ := call["resData"].(map[string]any)
+	records, _ := resData["record"].([]any)
 
 	for _, record := range records {
-		recordt := record.(map[string]any)
+		recordt, ok := record.(map[string]any)
+		if !ok {
+			continue
+		}
+		recordId, ok := recordt["id"].(float64)
+		if !ok {
+			continue
+		}
 
-		if d.Get("domain").(string)+":"+strconv.Itoa(int(recordt["id"].(float64))) == d.Id() {
+		if d.Get("domain").(string)+":"+strconv.Itoa(int(recordId)) == d.Id() {
+			recordType, _ := recordt["type"].(string)
+			content, _ := recordt["content"].(string)
 			d.Set("domain", d.Get("domain").(string))
-			d.Set("type", recordt["type"].(string))
-			d.Set("content", recordt["content"].(string))
+			d.Set("type", recordType)
+			flattenNameserverRecordContent(d, recordType, content)
 
-			if val, ok := recordt["name"]; ok {
-				d.Set("name", val.(string))
-			}
-			if val, ok := recordt["urlRedirectType"]; ok {
-				d.Set("url_redirect_type", val.(string))
+			if val, ok := recordt["name"].(string); ok {
+				d.Set("name", flattenNameserverRecordName(d.Get("name").(string), val, d.Get("domain").(string)))
 			}
-			if val, ok := recordt["urlRedirectType"]; ok {
-				d.Set("url_redirect_type", val.(string))
+			if val, ok := recordt["urlRedirectType"].(string); ok {
+				d.Set("url_redirect_type", val)
 			}
-			if val, ok := recordt["urlRedirectTitle"]; ok {
-				d.Set("url_redirect_title", val.(string))
+			if val, ok := recordt["urlRedirectTitle"].(string); ok {
+				d.Set("url_redirect_title", val)
 			}
-			if val, ok := recordt["urlRedirectDescription"]; ok {
-				d.Set("url_redirect_description", val.(string))
+			if val, ok := recordt["urlRedirectDescription"].(string); ok {
+				d.Set("url_redirect_description", val)
 			}
-			if val, ok := recordt["urlRedirectKeywords"]; ok {
-				d.Set("url_redirect_keywords", val.(string))
+			if val, ok := recordt["urlRedirectKeywords"].(string); ok {
+				d.Set("url_redirect_keywords", val)
 			}
-			if val, ok := recordt["urlRedirectFavIcon"]; ok {
-				d.Set("url_redirect_fav_icon", val.(string))
+			if val, ok := recordt["urlRedirectFavIcon"].(string); ok {
+				d.Set("url_redirect_fav_icon", val)
 			}
 			if val, ok := recordt["urlAppend"]; ok {
-				d.Set("url_append", val.(bool))
+				d.Set("url_append", api.IsTruthy(val))
 			}
-			if val, ok := recordt["testing"]; ok {
-				d.Set("testing", val.(bool))
+			if val, ok := recordt["ttl"].(float64); ok {
+				d.Set("ttl", int(val))
 			}
-			if val, ok := recordt["ttl"]; ok {
-				d.Set("ttl", val.(float64))
-			}
-			if val, ok := recordt["prio"]; ok {
-				d.Set("prio", val.(float64))
+			if val, ok := recordt["prio"].(float64); ok {
+				d.Set("prio", int(val))
 			}
 
 			return diags
 		}
 	}
 
+	// Records created in testing mode are never persisted, so they cannot be found by the (always live) read
+	if nameserverRecordTesting(d, client) {
+		return diags
+	}
+
 	// If the resource is not found, mark it as removed
 	d.SetId("")
 	return nil
@@ -319,7 +435,7 @@ func resourceNameserverRecordUpdate(ctx context.Context, d *schema.ResourceData,
 	var diags diag.Diagnostics
 	client := m.(*api.Client)
 
-	_, id, err := resourceNameserverRecordParseId(d.Id())
+	domain, id, err := resourceNameserverRecordParseId(d.Id())
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -333,21 +449,18 @@ func resourceNameserverRecordUpdate(ctx context.Context, d *schema.ResourceData,
 		"id": id,
 	}
 
-	if d.HasChange("type") {
-		parameters["type"] = d.Get("type").(string)
-	}
-	if d.HasChange("content") {
-		parameters["content"] = d.Get("content").(string)
+	if d.HasChanges("content", "weight", "port", "caa_flags", "caa_tag") {
+		parameters["content"] = expandNameserverRecordContent(d)
 	}
 
-	if name, ok := d.GetOk("name"); ok && d.HasChange("name") {
-		parameters["name"] = name
+	if d.HasChange("name") {
+		parameters["name"] = d.Get("name").(string)
 	}
 	if ttl, ok := d.GetOk("ttl"); ok && d.HasChange("ttl") {
 		parameters["ttl"] = ttl
 	}
-	if prio, ok := d.GetOk("prio"); ok && d.HasChange("prio") {
-		parameters["prio"] = prio
+	if d.HasChange("prio") && prioRecordTypes[d.Get("type").(string)] {
+		parameters["prio"] = d.Get("prio").(int)
 	}
 	if urlRedirectType, ok := d.GetOk("url_redirect_type"); ok && d.HasChange("url_redirect_type") {
 		parameters["urlRedirectType"] = urlRedirectType
@@ -364,14 +477,15 @@ func resourceNameserverRecordUpdate(ctx context.Context, d *schema.ResourceData,
 	if urlRedirectKeywords, ok := d.GetOk("url_redirect_keywords"); ok && d.HasChange("url_redirect_keywords") {
 		parameters["urlRedirectKeywords"] = urlRedirectKeywords
 	}
-	if urlAppend, ok := d.GetOk("url_append"); ok && d.HasChange("url_append") {
-		parameters["urlAppend"] = urlAppend
+	if d.HasChange("url_append") {
+		parameters["urlAppend"] = d.Get("url_append").(bool)
 	}
-	if testing, ok := d.GetOk("testing"); ok && d.HasChange("testing") {
-		parameters["testing"] = testing
+	if nameserverRecordTesting(d, client) {
+		parameters["testing"] = true
 	}
 
-	err = client.CallNoResponseBody(ctx, "nameserver.updateRecord", parameters)
+	call, err := client.Call(ctx, "nameserver.updateRecord", parameters)
+	client.InvalidateNameserverInfo(domain)
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -380,6 +494,14 @@ func resourceNameserverRecordUpdate(ctx context.Context, d *schema.ResourceData,
 		})
 		return diags
 	}
+	if !call.IsSuccess() {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not update nameserver record",
+			Detail:   fmt.Sprintf("API response not status code 1000 or 1001. Got response: %s", call.ErrorMessage()),
+		})
+		return diags
+	}
 
 	return resourceNameserverRecordRead(ctx, d, m)
 }
@@ -388,7 +510,7 @@ func resourceNameserverRecordDelete(ctx context.Context, d *schema.ResourceData,
 	var diags diag.Diagnostics
 	client := m.(*api.Client)
 
-	_, id, err := resourceNameserverRecordParseId(d.Id())
+	domain, id, err := resourceNameserverRecordParseId(d.Id())
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -402,11 +524,12 @@ func resourceNameserverRecordDelete(ctx context.Context, d *schema.ResourceData,
 		"id": id,
 	}
 
-	if testing, ok := d.GetOk("testing"); ok {
-		parameters["testing"] = testing
+	if nameserverRecordTesting(d, client) {
+		parameters["testing"] = true
 	}
 
 	err = client.CallNoResponseBody(ctx, "nameserver.deleteRecord", parameters)
+	client.InvalidateNameserverInfo(domain)
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -418,3 +541,425 @@ func resourceNameserverRecordDelete(ctx context.Context, d *schema.ResourceData,
 
 	return diags
 }
+
+// checkConflictingNameserverRecords returns an error if the zone contains a record at the name of the planned record,
+// which cannot exist together with it, e.g. a CNAME record. Zones that cannot be read, e.g. because they are created
+// in the same apply, are not checked. Records replaced in the plan are deleted first and never conflict.
+func checkConflictingNameserverRecords(ctx context.Context, client *api.Client, d *schema.ResourceDiff) error {
+	domain := d.Get("domain").(string)
+
+	call, err := client.NameserverInfo(ctx, domain)
+	if err != nil {
+		return fmt.Errorf("could not get nameserver info: %w", err)
+	}
+	if call.Code() != api.COMMAND_SUCCESSFUL {
+		return nil
+	}
+
+	recordType := d.Get("type").(string)
+	name := plannedRecordKey(domain, d.Get("name").(string))
+
+	// a record whose name or type changes is replaced. The diff of its replacement is planned again without
+	// the state and so without the id, which is why the replaced record is remembered by the client.
+	if d.Id() != "" {
+		client.SetReplacedRecord(d.Id())
+	}
+
+	resData, _ := call["resData"].(map[string]any)
+	records, _ := resData["record"].([]any)
+	for _, record := range records {
+		recordt, ok := record.(map[string]any)
+		if !ok {
+			continue
+		}
+		otherType, _ := recordt["type"].(string)
+		recordName, _ := recordt["name"].(string)
+		id, _ := recordt["id"].(float64)
+		if client.IsReplacedRecord(fmt.Sprintf("%s:%d", domain, int(id))) ||
+			plannedRecordKey(domain, recordName) != name || !recordTypesConflict(recordType, otherType) {
+			continue
+		}
+
+		return fmt.Errorf("%s records cannot exist together with the %s record %s:%d at %s",
+			recordType, otherType, domain, int(id), name)
+	}
+
+	return nil
+}
+
+// checkNameserverZoneAmbiguity returns an error if the account has more than one zone for the domain, in which case
+// the zone of a record cannot be determined by the domain alone and ro_id has to be set.
+func checkNameserverZoneAmbiguity(ctx context.Context, client *api.Client, domain string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	zones, call, err := client.CallAll(ctx, "nameserver.list", map[string]interface{}{
+		"domain": domain,
+	}, "domains")
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not list nameserver zones",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	if !call.IsSuccess() {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not list nameserver zones",
+			Detail:   fmt.Sprintf("API response not status code 1000. Got response: %s", call.ErrorMessage()),
+		})
+		return diags
+	}
+
+	var roIds []string
+	for _, zone := range zones {
+		zonet, ok := zone.(map[string]any)
+		// the domain parameter is a search pattern, only count exact matches
+		if !ok || !strings.EqualFold(fmt.Sprint(zonet["domain"]), domain) {
+			continue
+		}
+		if roId, ok := zonet["roId"].(float64); ok {
+			roIds = append(roIds, strconv.Itoa(int(roId)))
+		}
+	}
+
+	if len(roIds) > 1 {
+		diags = append(diags, diag.Diagnostic{
+			Severity:      diag.Error,
+			Summary:       "Ambiguous nameserver zone",
+			Detail:        fmt.Sprintf("The account has %d zones for domain %s, set ro_id to one of: %s", len(roIds), domain, strings.Join(roIds, ", ")),
+			AttributePath: cty.GetAttrPath("ro_id"),
+		})
+	}
+
+	return diags
+}
+
+// checkDuplicateNameserverRecord returns an error if the zone already contains a record with the name, type and
+// content of the record to create. Duplicates are created by the api without complaint.
+func checkDuplicateNameserverRecord(ctx context.Context, client *api.Client, d *schema.ResourceData, domain string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	client.InvalidateNameserverInfo(domain)
+	call, err := client.NameserverInfo(ctx, domain)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not get nameserver info",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	if call.Code() != api.COMMAND_SUCCESSFUL {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not get nameserver info",
+			Detail:   fmt.Sprintf("API response not status code 1000. Got response: %s", call.ErrorMessage()),
+		})
+		return diags
+	}
+
+	recordType := d.Get("type").(string)
+	name := plannedRecordKey(domain, d.Get("name").(string))
+	content := expandNameserverRecordContent(d)
+
+	resData, _ := call["resData"].(map[string]any)
+	records, _ := resData["record"].([]any)
+	for _, record := range records {
+		recordt, ok := record.(map[string]any)
+		if !ok || recordt["type"] != recordType {
+			continue
+		}
+		recordName, _ := recordt["name"].(string)
+		recordContent, _ := recordt["content"].(string)
+		if plannedRecordKey(domain, recordName) != name {
+			continue
+		}
+		if hostnameRecordTypes[recordType] {
+			if normalizeHostname(recordContent) != normalizeHostname(content) {
+				continue
+			}
+		} else if recordContent != content {
+			continue
+		}
+
+		id, _ := recordt["id"].(float64)
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Duplicate nameserver record",
+			Detail: fmt.Sprintf("The zone %s already contains the %s record %s with content %s. "+
+				"Import it with the id %s:%d instead of creating it again", domain, recordType, name, content, domain, int(id)),
+		})
+		return diags
+	}
+
+	return diags
+}
+
+// nameserverRecordTesting returns whether operations on the record run in testing mode. The testing mode of the
+// provider applies to all records, otherwise a record without testing in its configuration uses the testing mode of
+// its zone, if the zone was created in this run.
+func nameserverRecordTesting(d *schema.ResourceData, client *api.Client) bool {
+	if client.Testing {
+		return true
+	}
+
+	rawConfig := d.GetRawConfig()
+	if !rawConfig.IsNull() && rawConfig.IsKnown() && !rawConfig.GetAttr("testing").IsNull() {
+		return d.Get("testing").(bool)
+	}
+
+	return d.Get("testing").(bool) || client.IsTestingZone(d.Get("domain").(string))
+}
+
+func resourceNameserverRecordCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	recordType := d.Get("type").(string)
+
+	if !prioRecordTypes[recordType] {
+		if _, ok := d.GetOk("prio"); ok {
+			return fmt.Errorf("prio can only be set for MX, SRV and URI records")
+		}
+	}
+
+	if recordType != "SRV" {
+		if _, ok := d.GetOk("weight"); ok {
+			return fmt.Errorf("weight can only be set for SRV records")
+		}
+		if _, ok := d.GetOk("port"); ok {
+			return fmt.Errorf("port can only be set for SRV records")
+		}
+	}
+
+	if d.NewValueKnown("content") && d.NewValueKnown("port") {
+		_, hasPort := d.GetOk("port")
+		if err := validateNameserverRecordContent(recordType, d.Get("content").(string), hasPort); err != nil {
+			return err
+		}
+	}
+
+	if recordType != "CAA" {
+		if _, ok := d.GetOk("caa_flags"); ok {
+			return fmt.Errorf("caa_flags can only be set for CAA records")
+		}
+		if _, ok := d.GetOk("caa_tag"); ok {
+			return fmt.Errorf("caa_tag can only be set for CAA records")
+		}
+	} else if _, ok := d.GetOk("caa_tag"); !ok && d.NewValueKnown("content") {
+		content := d.Get("content").(string)
+		match := caaContentRegexp.FindStringSubmatch(content)
+		if match == nil {
+			return fmt.Errorf("content of CAA record must have the format <flags> <tag> \"<value>\" "+
+				"with tag one of issue, issuewild, iodef, got: %s", content)
+		}
+		if flags, _ := strconv.Atoi(match[1]); flags > 255 {
+			return fmt.Errorf("flags of CAA record must be between 0 and 255, got: %s", match[1])
+		}
+	}
+
+	if (d.Id() == "" || d.HasChanges("name", "type")) &&
+		d.NewValueKnown("domain") && d.NewValueKnown("name") && d.NewValueKnown("type") {
+		return checkConflictingNameserverRecords(ctx, m.(*api.Client), d)
+	}
+
+	return nil
+}
+
+// validateNameserverRecordContent checks that the content matches the record type, e.g. an IPv6 address for AAAA
+// records. Types without a simple content format are not checked.
+func validateNameserverRecordContent(recordType string, content string, hasPort bool) error {
+	switch recordType {
+	case "A":
+		if ip := net.ParseIP(content); ip == nil || ip.To4() == nil {
+			return fmt.Errorf("content of A record must be an IPv4 address, got: %s", content)
+		}
+	case "AAAA":
+		if ip := net.ParseIP(content); ip == nil || ip.To4() != nil {
+			return fmt.Errorf("content of AAAA record must be an IPv6 address, got: %s", content)
+		}
+	case "CNAME", "NS", "PTR", "ALIAS":
+		if !hostnameRegexp.MatchString(content) {
+			return fmt.Errorf("content of %s record must be a hostname, got: %s", recordType, content)
+		}
+	case "MX":
+		// a single dot is a null MX record (RFC 7505)
+		if content != "." && !hostnameRegexp.MatchString(content) {
+			return fmt.Errorf("content of MX record must be a hostname, the priority is set with prio, got: %s", content)
+		}
+	case "SRV":
+		if hasPort && content != "." && !hostnameRegexp.MatchString(content) {
+			return fmt.Errorf("content of SRV record with port must be the target hostname, got: %s", content)
+		}
+	}
+
+	return nil
+}
+
+// recordTypesConflict reports whether records of the two types cannot exist at the same name.
+func recordTypesConflict(recordType string, otherType string) bool {
+	if recordType == otherType {
+		return false
+	}
+	if recordType == "CNAME" || otherType == "CNAME" {
+		return true
+	}
+	for _, conflictingType := range recordTypeConflicts[recordType] {
+		if conflictingType == otherType {
+			return true
+		}
+	}
+	for _, conflictingType := range recordTypeConflicts[otherType] {
+		if conflictingType == recordType {
+			return true
+		}
+	}
+	return false
+}
+
+// plannedRecordKey returns the fully qualified name of a record.
+func plannedRecordKey(domain string, name string) string {
+	domain = normalizeHostname(domain)
+	name = normalizeHostname(name)
+
+	if isApexRecordName(name, domain) {
+		return domain
+	}
+	if !strings.HasSuffix(name, "."+domain) {
+		return name + "." + domain
+	}
+	return name
+}
+
+// flattenNameserverRecordName keeps the configured name if it is the relative form of the fully qualified name
+// returned by the api, e.g. _dmarc for _dmarc.example.com. Otherwise the name of the api is used as is.
+func flattenNameserverRecordName(configuredName string, serverName string, domain string) string {
+	if configuredName == "" {
+		return serverName
+	}
+	if strings.HasSuffix(configuredName, ".") {
+		return flattenHostname(configuredName, serverName)
+	}
+	serverHost := strings.TrimSuffix(serverName, ".")
+	if strings.EqualFold(serverHost, configuredName+"."+domain) || strings.EqualFold(serverHost, configuredName) {
+		return configuredName
+	}
+	return flattenHostname(configuredName, serverName)
+}
+
+// hostnameRecordTypes are the record types whose content is a hostname, for SRV records the target host.
+var hostnameRecordTypes = map[string]bool{
+	"CNAME": true,
+	"MX":    true,
+	"NS":    true,
+	"PTR":   true,
+	"SRV":   true,
+}
+
+// flattenHostname keeps the configured hostname if it only differs from the hostname returned by the api in
+// case or in the trailing dot, e.g. target.example.com. for target.example.com.
+func flattenHostname(configured string, server string) string {
+	if configured != "" && normalizeHostname(configured) == normalizeHostname(server) {
+		return configured
+	}
+	return server
+}
+
+// expandNameserverRecordContent builds the record content sent to the api from the structured attributes.
+func expandNameserverRecordContent(d *schema.ResourceData) string {
+	content := d.Get("content").(string)
+
+	switch d.Get("type").(string) {
+	case "SRV":
+		if port, ok := d.GetOk("port"); ok {
+			// the priority of SRV records is passed separately as prio
+			return fmt.Sprintf("%d %d %s", d.Get("weight").(int), port.(int), content)
+		}
+	case "CAA":
+		if tag, ok := d.GetOk("caa_tag"); ok {
+			return fmt.Sprintf("%d %s %q", d.Get("caa_flags").(int), tag.(string), content)
+		}
+	case "TXT":
+		if len(content) > txtSegmentLength && !strings.HasPrefix(content, "\"") {
+			return splitTxtContent(content)
+		}
+	}
+
+	return content
+}
+
+// flattenNameserverRecordContent sets the content and the structured attributes from the record content
+// returned by the api. Structured attributes are only used when they are already in use for the record.
+func flattenNameserverRecordContent(d *schema.ResourceData, recordType string, content string) {
+	switch recordType {
+	case "SRV":
+		if _, ok := d.GetOk("port"); ok {
+			parts := strings.Fields(content)
+			if len(parts) == 3 {
+				weight, weightErr := strconv.Atoi(parts[0])
+				port, portErr := strconv.Atoi(parts[1])
+				if weightErr == nil && portErr == nil {
+					d.Set("weight", weight)
+					d.Set("port", port)
+					d.Set("content", flattenHostname(d.Get("content").(string), parts[2]))
+					return
+				}
+			}
+		}
+	case "CAA":
+		if _, ok := d.GetOk("caa_tag"); ok {
+			if match := caaContentRegexp.FindStringSubmatch(content); match != nil {
+				flags, _ := strconv.Atoi(match[1])
+				d.Set("caa_flags", flags)
+				d.Set("caa_tag", match[2])
+				d.Set("content", match[3])
+				return
+			}
+		}
+	case "TXT":
+		// long TXT records are split on write, join them again unless the segments were configured explicitly
+		if !strings.HasPrefix(d.Get("content").(string), "\"") && txtSegmentsRegexp.MatchString(content) {
+			d.Set("content", joinTxtContent(content))
+			return
+		}
+	}
+
+	if hostnameRecordTypes[recordType] {
+		content = flattenHostname(d.Get("content").(string), content)
+	}
+	d.Set("content", content)
+}
+
+// splitTxtContent splits TXT content into quoted segments of at most 255 bytes.
+func splitTxtContent(content string) string {
+	var segments []string
+	for len(content) > txtSegmentLength {
+		// do not split a multi-byte character, unless the content is no valid UTF-8
+		end := txtSegmentLength
+		for end > 0 && !utf8.RuneStart(content[end]) {
+			end--
+		}
+		if end == 0 {
+			end = txtSegmentLength
+		}
+		segments = append(segments, content[:end])
+		content = content[end:]
+	}
+	segments = append(segments, content)
+
+	for i, segment := range segments {
+		segment = strings.ReplaceAll(segment, "\\", "\\\\")
+		segment = strings.ReplaceAll(segment, "\"", "\\\"")
+		segments[i] = "\"" + segment + "\""
+	}
+
+	return strings.Join(segments, " ")
+}
+
+// joinTxtContent is the inverse of splitTxtContent.
+func joinTxtContent(content string) string {
+	var builder strings.Builder
+	for _, match := range txtSegmentRegexp.FindAllStringSubmatch(content, -1) {
+		builder.WriteString(txtEscapeRegexp.ReplaceAllString(match[1], "$1"))
+	}
+	return builder.String()
+}
@@ -0,0 +1,545 @@
+package resource
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/inwx/terraform-provider-inwx/inwx/internal/api/apitest"
+)
+
+// nameserverInfo returns a nameserver.info response of example.com with records.
+func nameserverInfo(records ...map[string]interface{}) map[string]interface{} {
+	recordList := make([]interface{}, len(records))
+	for i, record := range records {
+		recordList[i] = record
+	}
+	return apitest.Success(map[string]interface{}{
+		"domain": "example.com",
+		"roId":   1,
+		"type":   "MASTER",
+		"record": recordList,
+	})
+}
+
+// nameserverList returns a nameserver.list response with one zone per roId of example.com.
+func nameserverList(roIds ...int) map[string]interface{} {
+	zones := make([]interface{}, len(roIds))
+	for i, roId := range roIds {
+		zones[i] = map[string]interface{}{"domain": "example.com", "roId": roId}
+	}
+	return apitest.Success(map[string]interface{}{
+		"count":   len(zones),
+		"domains": zones,
+	})
+}
+
+func TestResourceNameserverRecordCreate(t *testing.T) {
+	stub := apitest.NewStub()
+	stub.Respond("nameserver.list", nameserverList(1))
+	stub.Respond("nameserver.createRecord", apitest.Success(map[string]interface{}{"id": 17}))
+	stub.Respond("nameserver.info", nameserverInfo(map[string]interface{}{
+		"id": 17, "name": "www.example.com", "type": "A", "content": "192.0.2.1", "ttl": 3600, "prio": 0,
+	}))
+	client := stub.Client(t)
+
+	d := planData(t, NameserverRecordResource(), "", nil, map[string]interface{}{
+		"domain":  "example.com",
+		"name":    "www",
+		"type":    "A",
+		"content": "192.0.2.1",
+	}, client)
+	requireNoErrors(t, resourceNameserverRecordCreate(context.Background(), d, client))
+
+	if d.Id() != "example.com:17" {
+		t.Errorf("expected id example.com:17, got %s", d.Id())
+	}
+	params := stub.LastCall("nameserver.createRecord")
+	if params["domain"] != "example.com" || params["name"] != "www" || params["type"] != "A" ||
+		params["content"] != "192.0.2.1" || params["ttl"] != float64(3600) {
+		t.Errorf("unexpected nameserver.createRecord parameters %v", params)
+	}
+	if _, ok := params["prio"]; ok {
+		t.Errorf("expected no prio for an A record, got %v", params["prio"])
+	}
+	if d.Get("name") != "www" || d.Get("ttl") != 3600 {
+		t.Errorf("unexpected state after create: name %v, ttl %v", d.Get("name"), d.Get("ttl"))
+	}
+}
+
+func TestResourceNameserverRecordCreateWithoutId(t *testing.T) {
+	stub := apitest.NewStub()
+	stub.Respond("nameserver.list", nameserverList(1))
+	stub.Respond("nameserver.createRecord", apitest.Success(map[string]interface{}{}))
+	stub.Respond("nameserver.info", nameserverInfo())
+	client := stub.Client(t)
+
+	d := planData(t, NameserverRecordResource(), "", nil, map[string]interface{}{
+		"domain":  "example.com",
+		"name":    "missing-id",
+		"type":    "A",
+		"content": "192.0.2.1",
+	}, client)
+	diags := resourceNameserverRecordCreate(context.Background(), d, client)
+	if !diags.HasError() || !strings.Contains(diags[0].Detail, "API response contains no id") {
+		t.Fatalf("expected error about the missing id, got %v", diags)
+	}
+}
+
+func TestResourceNameserverRecordCreateTesting(t *testing.T) {
+	for _, testingMode := range []bool{true, false} {
+		stub := apitest.NewStub()
+		stub.Respond("nameserver.list", nameserverList(1))
+		stub.Respond("nameserver.createRecord", apitest.Success(map[string]interface{}{"id": 17}))
+		stub.Respond("nameserver.info", nameserverInfo())
+		client := stub.Client(t)
+
+		d := planData(t, NameserverRecordResource(), "", nil, map[string]interface{}{
+			"domain":  "example.com",
+			"name":    "www",
+			"type":    "A",
+			"content": "192.0.2.1",
+			"testing": testingMode,
+		}, client)
+		requireNoErrors(t, resourceNameserverRecordCreate(context.Background(), d, client))
+
+		if got := stub.LastCall("nameserver.createRecord")["testing"]; (got == true) != testingMode {
+			t.Errorf("expected testing %v to be sent as configured, got %v", testingMode, got)
+		}
+		// a record created in testing mode is not in the zone, but kept in state
+		if (d.Id() != "") != testingMode {
+			t.Errorf("unexpected id %q after create with testing %v", d.Id(), testingMode)
+		}
+	}
+}
+
+func TestResourceNameserverRecordTestingZone(t *testing.T) {
+	stub := apitest.NewStub()
+	stub.Respond("nameserver.create", apitest.Success(map[string]interface{}{"roId": 1}))
+	stub.Respond("nameserver.list", nameserverList(1))
+	stub.Respond("nameserver.createRecord", apitest.Success(map[string]interface{}{"id": 17}))
+	stub.Respond("nameserver.info", nameserverInfo(soaRecord))
+	client := stub.Client(t)
+
+	zone := planData(t, NameserverResource(), "", nil, nameserverState(map[string]interface{}{"testing": true}), client)
+	requireNoErrors(t, resourceNameserverCreate(context.Background(), zone, client))
+	if got := stub.LastCall("nameserver.create")["testing"]; got != true {
+		t.Fatalf("expected the zone to be created in testing mode, got %v", got)
+	}
+
+	tests := []struct {
+		name    string
+		domain  string
+		testing interface{}
+		want    bool
+	}{
+		{name: "inherited from the zone", domain: "example.com", want: true},
+		{name: "overridden by the record", domain: "example.com", testing: false, want: false},
+		{name: "other zone", domain: "example.org", want: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := map[string]interface{}{
+				"domain":  test.domain,
+				"name":    "www",
+				"type":    "A",
+				"content": "192.0.2.1",
+			}
+			if test.testing != nil {
+				config["testing"] = test.testing
+			}
+
+			d := planData(t, NameserverRecordResource(), "", nil, config, client)
+			requireNoErrors(t, resourceNameserverRecordCreate(context.Background(), d, client))
+
+			if got := stub.LastCall("nameserver.createRecord")["testing"]; (got == true) != test.want {
+				t.Errorf("expected testing %v, got %v", test.want, got)
+			}
+			// a record created in testing mode is not in the zone, but kept in state
+			if (d.Id() != "") != test.want {
+				t.Errorf("unexpected id %q with testing %v", d.Id(), test.want)
+			}
+		})
+	}
+}
+
+func TestResourceNameserverRecordPlanConflicting(t *testing.T) {
+	tests := []struct {
+		name       string
+		recordType string
+		content    string
+		existing   map[string]interface{}
+		conflict   bool
+	}{
+		{
+			name: "cname at a name with an A record", recordType: "CNAME", content: "example.net",
+			existing: map[string]interface{}{"id": 18, "name": "www.example.com", "type": "A", "content": "192.0.2.1"},
+			conflict: true,
+		},
+		{
+			name: "A record at a name with a cname", recordType: "A", content: "192.0.2.1",
+			existing: map[string]interface{}{"id": 18, "name": "WWW.example.com.", "type": "CNAME", "content": "example.net"},
+			conflict: true,
+		},
+		{
+			name: "url at a name with an AAAA record", recordType: "URL", content: "https://example.net",
+			existing: map[string]interface{}{"id": 18, "name": "www.example.com", "type": "AAAA", "content": "2001:db8::1"},
+			conflict: true,
+		},
+		{
+			name: "A record at a name with a url", recordType: "A", content: "192.0.2.1",
+			existing: map[string]interface{}{"id": 18, "name": "www.example.com", "type": "URL", "content": "https://example.net"},
+			conflict: true,
+		},
+		{
+			name: "A record at a name with a TXT record", recordType: "A", content: "192.0.2.1",
+			existing: map[string]interface{}{"id": 18, "name": "www.example.com", "type": "TXT", "content": "v=spf1 -all"},
+		},
+		{
+			name: "cname at another name", recordType: "CNAME", content: "example.net",
+			existing: map[string]interface{}{"id": 18, "name": "mail.example.com", "type": "A", "content": "192.0.2.1"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stub := apitest.NewStub()
+			stub.Respond("nameserver.info", nameserverInfo(test.existing))
+
+			_, _, err := diffResource(t, NameserverRecordResource(), "", nil, map[string]interface{}{
+				"domain":  "example.com",
+				"name":    "www",
+				"type":    test.recordType,
+				"content": test.content,
+			}, stub.Client(t))
+
+			if test.conflict && (err == nil || !strings.Contains(err.Error(), "example.com:18")) {
+				t.Errorf("expected the conflict with record 18 to fail the plan, got %v", err)
+			}
+			if !test.conflict && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestResourceNameserverRecordPlanConflictingSkipped(t *testing.T) {
+	cname := map[string]interface{}{"id": 18, "name": "www.example.com", "type": "CNAME", "content": "example.net"}
+	config := map[string]interface{}{
+		"domain":  "example.com",
+		"name":    "www",
+		"type":    "A",
+		"content": "192.0.2.1",
+	}
+
+	t.Run("zone created in the same apply", func(t *testing.T) {
+		stub := apitest.NewStub()
+		stub.Respond("nameserver.info", apitest.Error(2303, "Object does not exist"))
+
+		if _, _, err := diffResource(t, NameserverRecordResource(), "", nil, config, stub.Client(t)); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("record replaced by itself", func(t *testing.T) {
+		stub := apitest.NewStub()
+		stub.Respond("nameserver.info", nameserverInfo(cname))
+
+		state := map[string]interface{}{"domain": "example.com", "name": "www", "type": "CNAME", "content": "example.net", "ttl": 3600}
+		if _, _, err := diffResource(t, NameserverRecordResource(), "example.com:18", state, config, stub.Client(t)); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("unchanged record", func(t *testing.T) {
+		stub := apitest.NewStub()
+		stub.Respond("nameserver.info", nameserverInfo(cname))
+
+		state := map[string]interface{}{"domain": "example.com", "name": "www", "type": "A", "content": "192.0.2.1", "ttl": 3600}
+		if _, _, err := diffResource(t, NameserverRecordResource(), "example.com:17", state, config, stub.Client(t)); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if calls := stub.Calls("nameserver.info"); len(calls) != 0 {
+			t.Errorf("expected no conflict check of an unchanged record, got %v", calls)
+		}
+	})
+}
+
+func TestResourceNameserverRecordCreateConflictingSameApply(t *testing.T) {
+	stub := apitest.NewStub()
+	stub.Respond("nameserver.list", nameserverList(1))
+	// neither record exists when planning
+	stub.Respond("nameserver.info", nameserverInfo())
+	stub.Respond("nameserver.createRecord", apitest.Success(map[string]interface{}{"id": 17}))
+	client := stub.Client(t)
+
+	url := planData(t, NameserverRecordResource(), "", nil, map[string]interface{}{
+		"domain":  "example.com",
+		"name":    "www",
+		"type":    "URL",
+		"content": "https://example.net",
+	}, client)
+	a := planData(t, NameserverRecordResource(), "", nil, map[string]interface{}{
+		"domain":  "example.com",
+		"name":    "www.example.com",
+		"type":    "A",
+		"content": "192.0.2.1",
+	}, client)
+	other := planData(t, NameserverRecordResource(), "", nil, map[string]interface{}{
+		"domain":  "example.com",
+		"name":    "mail",
+		"type":    "A",
+		"content": "192.0.2.1",
+	}, client)
+
+	requireNoErrors(t, resourceNameserverRecordCreate(context.Background(), url, client))
+	diags := resourceNameserverRecordCreate(context.Background(), a, client)
+	if !diags.HasError() || diags[0].Summary != "Conflicting nameserver record" || !strings.Contains(diags[0].Detail, "URL record at www.example.com") {
+		t.Fatalf("expected the A record to conflict with the URL record of the same apply, got %v", diags)
+	}
+	requireNoErrors(t, resourceNameserverRecordCreate(context.Background(), other, client))
+
+	if calls := stub.Calls("nameserver.createRecord"); len(calls) != 2 {
+		t.Errorf("expected only the records without conflict to be created, got %v", calls)
+	}
+}
+
+func TestResourceNameserverRecordCreateAmbiguousZone(t *testing.T) {
+	config := map[string]interface{}{
+		"domain":  "example.com",
+		"name":    "www",
+		"type":    "A",
+		"content": "192.0.2.1",
+	}
+
+	t.Run("without ro_id", func(t *testing.T) {
+		stub := apitest.NewStub()
+		stub.Respond("nameserver.list", nameserverList(1, 2))
+		client := stub.Client(t)
+
+		d := planData(t, NameserverRecordResource(), "", nil, config, client)
+		diags := resourceNameserverRecordCreate(context.Background(), d, client)
+		if !diags.HasError() || diags[0].Summary != "Ambiguous nameserver zone" || !strings.Contains(diags[0].Detail, "1, 2") {
+			t.Fatalf("expected an ambiguous zone error listing the zones, got %v", diags)
+		}
+		if calls := stub.Calls("nameserver.createRecord"); len(calls) != 0 {
+			t.Errorf("expected no nameserver.createRecord, got %v", calls)
+		}
+	})
+
+	t.Run("with ro_id", func(t *testing.T) {
+		stub := apitest.NewStub()
+		stub.Respond("nameserver.list", nameserverList(1, 2))
+		stub.Respond("nameserver.createRecord", apitest.Success(map[string]interface{}{"id": 17}))
+		stub.Respond("nameserver.info", nameserverInfo(map[string]interface{}{
+			"id": 17, "name": "www.example.com", "type": "A", "content": "192.0.2.1", "ttl": 3600,
+		}))
+		client := stub.Client(t)
+
+		withRoId := map[string]interface{}{"ro_id": 2}
+		for key, value := range config {
+			withRoId[key] = value
+		}
+		d := planData(t, NameserverRecordResource(), "", nil, withRoId, client)
+		requireNoErrors(t, resourceNameserverRecordCreate(context.Background(), d, client))
+
+		if calls := stub.Calls("nameserver.list"); len(calls) != 0 {
+			t.Errorf("expected no zone lookup with ro_id set, got %v", calls)
+		}
+		if got := stub.LastCall("nameserver.createRecord")["roId"]; got != float64(2) {
+			t.Errorf("expected roId 2, got %v", got)
+		}
+	})
+}
+
+func TestResourceNameserverRecordRead(t *testing.T) {
+	stub := apitest.NewStub()
+	stub.Respond("nameserver.info", nameserverInfo(
+		map[string]interface{}{"id": 16, "name": "example.com", "type": "SOA", "content": "ns.inwx.de hostmaster.example.com 2024010101 10800 3600 604800 3600"},
+		map[string]interface{}{"id": 17, "name": "example.com", "type": "MX", "content": "mail.example.com", "ttl": 300, "prio": 10},
+	))
+
+	d := stateData(t, NameserverRecordResource(), "example.com:17", map[string]interface{}{"domain": "example.com"})
+	requireNoErrors(t, resourceNameserverRecordRead(context.Background(), d, stub.Client(t)))
+
+	if d.Id() != "example.com:17" {
+		t.Fatalf("expected the record to be found, got id %q", d.Id())
+	}
+	for key, want := range map[string]interface{}{"type": "MX", "content": "mail.example.com", "name": "example.com", "ttl": 300, "prio": 10} {
+		if got := d.Get(key); got != want {
+			t.Errorf("expected %s %v, got %v", key, want, got)
+		}
+	}
+}
+
+func TestResourceNameserverRecordReadRemoved(t *testing.T) {
+	stub := apitest.NewStub()
+	stub.Respond("nameserver.info", nameserverInfo(
+		map[string]interface{}{"id": 18, "name": "www.example.com", "type": "A", "content": "192.0.2.1"},
+	))
+
+	d := stateData(t, NameserverRecordResource(), "example.com:17", map[string]interface{}{"domain": "example.com"})
+	requireNoErrors(t, resourceNameserverRecordRead(context.Background(), d, stub.Client(t)))
+
+	if d.Id() != "" {
+		t.Errorf("expected a removed record to be removed from state, got id %s", d.Id())
+	}
+}
+
+func TestResourceNameserverRecordReadMalformedRecords(t *testing.T) {
+	stub := apitest.NewStub()
+	stub.Respond("nameserver.info", apitest.Success(map[string]interface{}{
+		"record": []interface{}{
+			"not a record",
+			map[string]interface{}{"name": "no-id.example.com", "type": "A"},
+			map[string]interface{}{"id": 17, "type": "A", "content": "192.0.2.1", "ttl": "3600"},
+		},
+	}))
+
+	d := stateData(t, NameserverRecordResource(), "example.com:17", map[string]interface{}{"domain": "example.com"})
+	requireNoErrors(t, resourceNameserverRecordRead(context.Background(), d, stub.Client(t)))
+
+	if d.Get("content") != "192.0.2.1" {
+		t.Errorf("expected content 192.0.2.1, got %v", d.Get("content"))
+	}
+}
+
+func TestResourceNameserverRecordTypeChangeForceNew(t *testing.T) {
+	state := map[string]interface{}{
+		"domain":  "example.com",
+		"name":    "www",
+		"type":    "CNAME",
+		"content": "example.org",
+		"ttl":     3600,
+	}
+	config := map[string]interface{}{
+		"domain":  "example.com",
+		"name":    "www",
+		"type":    "A",
+		"content": "192.0.2.1",
+	}
+
+	_, diff, err := diffResource(t, NameserverRecordResource(), "example.com:17", state, config, apitest.NewStub().Client(t))
+	if err != nil {
+		t.Fatalf("could not plan: %v", err)
+	}
+	if diff == nil || !diff.RequiresNew() {
+		t.Fatalf("expected a type change to replace the record, got %v", diff)
+	}
+	if attr := diff.Attributes["type"]; attr == nil || !attr.RequiresNew {
+		t.Errorf("expected type to require replacement, got %v", attr)
+	}
+}
+
+func TestFlattenNameserverRecordName(t *testing.T) {
+	tests := []struct {
+		configured string
+		server     string
+		want       string
+	}{
+		{configured: "", server: "www.example.com", want: "www.example.com"},
+		{configured: "www", server: "www.example.com", want: "www"},
+		{configured: "www", server: "www.example.com.", want: "www"},
+		{configured: "WWW", server: "www.example.com", want: "WWW"},
+		{configured: "www.example.com", server: "www.example.com", want: "www.example.com"},
+		{configured: "WWW.Example.com", server: "www.example.com", want: "WWW.Example.com"},
+		{configured: "www.example.com.", server: "www.example.com", want: "www.example.com."},
+		{configured: "_dmarc", server: "_dmarc.example.com", want: "_dmarc"},
+		{configured: "www", server: "mail.example.com", want: "mail.example.com"},
+		{configured: "www.example.com.", server: "mail.example.com", want: "mail.example.com"},
+	}
+
+	for _, test := range tests {
+		if got := flattenNameserverRecordName(test.configured, test.server, "example.com"); got != test.want {
+			t.Errorf("flattenNameserverRecordName(%q, %q) = %q, want %q", test.configured, test.server, got, test.want)
+		}
+	}
+}
+
+func TestFlattenHostname(t *testing.T) {
+	tests := []struct {
+		configured string
+		server     string
+		want       string
+	}{
+		{configured: "", server: "mail.example.com", want: "mail.example.com"},
+		{configured: "mail.example.com", server: "mail.example.com", want: "mail.example.com"},
+		{configured: "mail.example.com.", server: "mail.example.com", want: "mail.example.com."},
+		{configured: "mail.example.com", server: "mail.example.com.", want: "mail.example.com"},
+		{configured: "Mail.Example.com", server: "mail.example.com", want: "Mail.Example.com"},
+		{configured: "mail.example.com", server: "mail2.example.com", want: "mail2.example.com"},
+	}
+
+	for _, test := range tests {
+		if got := flattenHostname(test.configured, test.server); got != test.want {
+			t.Errorf("flattenHostname(%q, %q) = %q, want %q", test.configured, test.server, got, test.want)
+		}
+	}
+}
+
+func TestFlattenNameserverRecordContentSrvTarget(t *testing.T) {
+	tests := []struct {
+		configured string
+		server     string
+		want       string
+	}{
+		{configured: "sip.example.com", server: "10 5060 sip.example.com.", want: "sip.example.com"},
+		{configured: "SIP.example.com.", server: "10 5060 sip.example.com", want: "SIP.example.com."},
+		{configured: "sip.example.com", server: "10 5060 sip2.example.com", want: "sip2.example.com"},
+	}
+
+	for _, test := range tests {
+		d := stateData(t, NameserverRecordResource(), "example.com:17", map[string]interface{}{
+			"domain":  "example.com",
+			"type":    "SRV",
+			"content": test.configured,
+			"port":    5061,
+		})
+		flattenNameserverRecordContent(d, "SRV", test.server)
+
+		if got := d.Get("content"); got != test.want {
+			t.Errorf("content of %q for configured %q = %q, want %q", test.server, test.configured, got, test.want)
+		}
+		if d.Get("port") != 5060 || d.Get("weight") != 10 {
+			t.Errorf("expected port 5060 and weight 10 of %q, got %v and %v", test.server, d.Get("port"), d.Get("weight"))
+		}
+	}
+}
+
+func TestSplitTxtContent(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		segments []string
+	}{
+		{name: "short", content: "v=spf1 -all", segments: []string{"v=spf1 -all"}},
+		{name: "ascii", content: strings.Repeat("a", 300), segments: []string{strings.Repeat("a", 255), strings.Repeat("a", 45)}},
+		// ä is 2 bytes, the 128th one would be split at byte 255
+		{name: "multi-byte", content: "a" + strings.Repeat("ä", 200), segments: []string{"a" + strings.Repeat("ä", 127), strings.Repeat("ä", 73)}},
+		{name: "quotes", content: `say "hi"`, segments: []string{`say \"hi\"`}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			split := splitTxtContent(test.content)
+
+			matches := txtSegmentRegexp.FindAllStringSubmatch(split, -1)
+			if len(matches) != len(test.segments) {
+				t.Fatalf("expected %d segments, got %q", len(test.segments), split)
+			}
+			for i, match := range matches {
+				if match[1] != test.segments[i] {
+					t.Errorf("segment %d: expected %q, got %q", i, test.segments[i], match[1])
+				}
+				if len(match[1]) > txtSegmentLength || !utf8.ValidString(match[1]) {
+					t.Errorf("segment %d is no valid segment: %q", i, match[1])
+				}
+			}
+			if joined := joinTxtContent(split); joined != test.content {
+				t.Errorf("expected the joined content to be the original content, got %q", joined)
+			}
+		})
+	}
+}
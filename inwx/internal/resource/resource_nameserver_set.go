@@ -0,0 +1,219 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/inwx/terraform-provider-inwx/inwx/internal/api"
+	"strconv"
+)
+
+func NameserverSetResource() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceNameserverSetCreate,
+		ReadContext:   resourceNameserverSetRead,
+		UpdateContext: resourceNameserverSetUpdate,
+		DeleteContext: resourceNameserverSetDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Description: "Name of the nameserver set",
+				Type:        schema.TypeString,
+				Required:    true,
+			},
+			"nameservers": {
+				Description: "Nameservers of the set",
+				Type:        schema.TypeList,
+				Required:    true,
+				MinItems:    1,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+		},
+	}
+}
+
+func resourceNameserverSetCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	client := m.(*api.Client)
+
+	parameters := map[string]interface{}{
+		"name": d.Get("name").(string),
+		"ns":   d.Get("nameservers").([]interface{}),
+	}
+
+	call, err := client.Call(ctx, "nsset.create", parameters)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not create nameserver set",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	if !call.IsSuccess() {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not create nameserver set",
+			Detail:   fmt.Sprintf("API response not status code 1000 or 1001. Got response: %s", call.ErrorMessage()),
+		})
+		return diags
+	}
+
+	resData, _ := call["resData"].(map[string]interface{})
+	id, ok := resData["id"].(float64)
+	if !ok {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not create nameserver set",
+			Detail:   fmt.Sprintf("API response contains no id. Got response: %s", call.ApiError()),
+		})
+		return diags
+	}
+	d.SetId(strconv.Itoa(int(id)))
+
+	if client.Testing {
+		return diags
+	}
+
+	return resourceNameserverSetRead(ctx, d, m)
+}
+
+func resourceNameserverSetRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	client := m.(*api.Client)
+
+	id, err := strconv.Atoi(d.Id())
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not read numerical nameserver set id",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	call, err := client.Call(ctx, "nsset.info", map[string]interface{}{
+		"id": id,
+	})
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not get nameserver set info",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	if call.Code() == 2303 { // Object does not exist
+		d.SetId("")
+		return diags
+	}
+	if call.Code() != api.COMMAND_SUCCESSFUL {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not get nameserver set info",
+			Detail:   fmt.Sprintf("API response not status code 1000. Got response: %s", call.ErrorMessage()),
+		})
+		return diags
+	}
+
+	resData, _ := call["resData"].(map[string]interface{})
+	if name, ok := resData["name"].(string); ok {
+		d.Set("name", name)
+	}
+	if nameservers, ok := resData["ns"].([]interface{}); ok {
+		d.Set("nameservers", flattenNameservers(d.Get("nameservers").([]interface{}), nameservers))
+	}
+
+	return diags
+}
+
+func resourceNameserverSetUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	client := m.(*api.Client)
+
+	id, err := strconv.Atoi(d.Id())
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not read numerical nameserver set id",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	parameters := map[string]interface{}{
+		"id": id,
+	}
+	if d.HasChange("name") {
+		parameters["name"] = d.Get("name").(string)
+	}
+	if d.HasChange("nameservers") {
+		parameters["ns"] = d.Get("nameservers").([]interface{})
+	}
+
+	call, err := client.Call(ctx, "nsset.update", parameters)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not update nameserver set",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	if !call.IsSuccess() {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not update nameserver set",
+			Detail:   fmt.Sprintf("API response not status code 1000 or 1001. Got response: %s", call.ErrorMessage()),
+		})
+		return diags
+	}
+
+	if client.Testing {
+		return diags
+	}
+
+	return resourceNameserverSetRead(ctx, d, m)
+}
+
+func resourceNameserverSetDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	client := m.(*api.Client)
+
+	id, err := strconv.Atoi(d.Id())
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not read numerical nameserver set id",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	call, err := client.Call(ctx, "nsset.delete", map[string]interface{}{
+		"id": id,
+	})
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not delete nameserver set",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	if !call.IsSuccess() {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not delete nameserver set",
+			Detail:   fmt.Sprintf("API response not status code 1000 or 1001. Got response: %s", call.ErrorMessage()),
+		})
+		return diags
+	}
+
+	return diags
+}
@@ -0,0 +1,255 @@
+package resource
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/inwx/terraform-provider-inwx/inwx/internal/api/apitest"
+)
+
+// soaRecord is the SOA record of example.com as returned by nameserver.info.
+var soaRecord = map[string]interface{}{
+	"id": 16, "name": "example.com", "type": "SOA", "content": "ns.inwx.de hostmaster.example.com 2024010101 10800 3600 604800 3600",
+}
+
+// nameserverState returns the state of the MASTER zone example.com with the given attributes added.
+func nameserverState(attributes map[string]interface{}) map[string]interface{} {
+	state := map[string]interface{}{
+		"domain":      "example.com",
+		"type":        "MASTER",
+		"nameservers": []interface{}{"ns.inwx.de", "ns2.inwx.de"},
+	}
+	for key, value := range attributes {
+		state[key] = value
+	}
+	return state
+}
+
+func TestResourceNameserverUpdateSoaKeepsSerial(t *testing.T) {
+	stub := apitest.NewStub()
+	stub.Respond("nameserver.info", nameserverInfo(soaRecord))
+	stub.Respond("nameserver.updateRecord", apitest.Success(nil))
+	client := stub.Client(t)
+
+	d := planData(t, NameserverResource(), "example.com:1",
+		nameserverState(map[string]interface{}{"soa_refresh": 10800, "soa_serial": 2024010101}),
+		nameserverState(map[string]interface{}{"soa_refresh": 7200}), client)
+	requireNoErrors(t, resourceNameserverUpdate(context.Background(), d, client))
+
+	params := stub.LastCall("nameserver.updateRecord")
+	if params["id"] != float64(16) || params["content"] != "ns.inwx.de hostmaster.example.com 2024010101 7200 3600 604800 3600" {
+		t.Errorf("unexpected nameserver.updateRecord parameters %v", params)
+	}
+	if got := d.Get("soa_serial"); got != 2024010101 {
+		t.Errorf("expected soa_serial to be read from the SOA record, got %v", got)
+	}
+}
+
+func TestResourceNameserverUpdateMasterIp(t *testing.T) {
+	stub := apitest.NewStub()
+	stub.Respond("nameserver.update", apitest.Success(nil))
+	stub.Respond("nameserver.info", nameserverInfo(soaRecord))
+	client := stub.Client(t)
+
+	_, diff, err := diffResource(t, NameserverResource(), "example.com:1",
+		nameserverState(map[string]interface{}{"type": "SLAVE", "master_ip": "192.0.2.1"}),
+		nameserverState(map[string]interface{}{"type": "SLAVE", "master_ip": "192.0.2.2"}), client)
+	if err != nil {
+		t.Fatalf("could not plan: %v", err)
+	}
+	if diff.RequiresNew() {
+		t.Error("expected a master_ip change to update the zone in place")
+	}
+
+	d := planData(t, NameserverResource(), "example.com:1",
+		nameserverState(map[string]interface{}{"type": "SLAVE", "master_ip": "192.0.2.1"}),
+		nameserverState(map[string]interface{}{"type": "SLAVE", "master_ip": "192.0.2.2"}), client)
+	requireNoErrors(t, resourceNameserverUpdate(context.Background(), d, client))
+
+	params := stub.LastCall("nameserver.update")
+	if params["domain"] != "example.com" || params["masterIp"] != "192.0.2.2" || len(params) != 2 {
+		t.Errorf("unexpected nameserver.update parameters %v", params)
+	}
+	if calls := stub.Calls("nameserver.updateRecord"); len(calls) != 0 {
+		t.Errorf("expected no records to be changed, got %v", calls)
+	}
+}
+
+func TestResourceNameserverWebMailForceNew(t *testing.T) {
+	for _, attribute := range []string{"web", "mail"} {
+		_, diff, err := diffResource(t, NameserverResource(), "example.com:1",
+			nameserverState(map[string]interface{}{attribute: "192.0.2.1"}),
+			nameserverState(map[string]interface{}{attribute: "192.0.2.2"}), nil)
+		if err != nil {
+			t.Fatalf("could not plan: %v", err)
+		}
+		if !diff.RequiresNew() {
+			t.Errorf("expected a %s change to recreate the zone", attribute)
+		}
+	}
+}
+
+func TestTransformRname(t *testing.T) {
+	tests := map[string]string{
+		"hostmaster.example.com.":   "hostmaster@example.com",
+		"hostmaster.example.com":    "hostmaster@example.com",
+		`first\.last.example.com.`:  "first.last@example.com",
+		`a\.b\.c.mail.example.com.`: "a.b.c@mail.example.com",
+		"localhost":                 "localhost",
+	}
+
+	for rname, want := range tests {
+		if got := transformRname(rname); got != want {
+			t.Errorf("transformRname(%q) = %q, want %q", rname, got, want)
+		}
+	}
+}
+
+func TestResourceNameserverReadSoaMail(t *testing.T) {
+	stub := apitest.NewStub()
+	stub.Respond("nameserver.info", nameserverInfo(map[string]interface{}{
+		"id": 16, "name": "example.com", "type": "SOA", "content": `ns.inwx.de first\.last.example.com 2024010101 10800 3600 604800 3600`,
+	}))
+
+	d := stateData(t, NameserverResource(), "example.com:1", nameserverState(nil))
+	requireNoErrors(t, resourceNameserverRead(context.Background(), d, stub.Client(t)))
+
+	if got := d.Get("soa_mail"); got != "first.last@example.com" {
+		t.Errorf("expected soa_mail first.last@example.com, got %v", got)
+	}
+}
+
+func TestResourceNameserverReadDelegatedSubzone(t *testing.T) {
+	stub := apitest.NewStub()
+	stub.Respond("nameserver.info", nameserverInfo(soaRecord,
+		map[string]interface{}{"id": 17, "name": "example.com", "type": "NS", "content": "ns.inwx.de"},
+		map[string]interface{}{"id": 18, "name": "", "type": "NS", "content": "ns2.inwx.de"},
+		map[string]interface{}{"id": 19, "name": "sub.example.com", "type": "NS", "content": "ns1.example.net"},
+		map[string]interface{}{"id": 20, "name": "sub.example.com", "type": "NS", "content": "ns2.example.net"},
+	))
+	client := stub.Client(t)
+
+	d := stateData(t, NameserverResource(), "example.com:1", nameserverState(nil))
+	requireNoErrors(t, resourceNameserverRead(context.Background(), d, client))
+
+	nameservers := d.Get("nameservers").([]interface{})
+	if len(nameservers) != 2 || nameservers[0] != "ns.inwx.de" || nameservers[1] != "ns2.inwx.de" {
+		t.Errorf("expected only the nameservers of the apex, got %v", nameservers)
+	}
+
+	_, diff, err := diffResource(t, NameserverResource(), "example.com:1", nameserverState(nil), map[string]interface{}{
+		"domain":      "example.com",
+		"type":        "MASTER",
+		"nameservers": []interface{}{"ns.inwx.de", "ns2.inwx.de"},
+	}, client)
+	if err != nil {
+		t.Fatalf("could not plan: %v", err)
+	}
+	if diff != nil {
+		for key := range diff.Attributes {
+			if strings.HasPrefix(key, "nameservers") {
+				t.Errorf("expected no nameservers diff for a delegated subzone, got %s: %v", key, diff.Attributes[key])
+			}
+		}
+	}
+}
+
+func TestResourceNameserverReadUrlRedirect(t *testing.T) {
+	stub := apitest.NewStub()
+	stub.Respond("nameserver.info", nameserverInfo(soaRecord,
+		map[string]interface{}{
+			"id": 17, "name": "www.example.com", "type": "URL", "content": "https://other.example.org",
+			"urlRedirectType": "FRAME", "urlRedirectTitle": "Other",
+		},
+		map[string]interface{}{
+			"id": 18, "name": "example.com", "type": "URL", "content": "https://example.org",
+			"urlRedirectType": "HEADER301", "urlRedirectTitle": "Example", "urlRedirectFavIcon": "https://example.org/favicon.ico",
+		},
+	))
+
+	d := stateData(t, NameserverResource(), "example.com:1", nameserverState(nil))
+	requireNoErrors(t, resourceNameserverRead(context.Background(), d, stub.Client(t)))
+
+	for key, want := range map[string]string{
+		"url_redirect_type":     "HEADER301",
+		"url_redirect_title":    "Example",
+		"url_redirect_fav_icon": "https://example.org/favicon.ico",
+	} {
+		if got := d.Get(key); got != want {
+			t.Errorf("expected %s %q from the apex URL record, got %q", key, want, got)
+		}
+	}
+}
+
+func TestIsApexRecordName(t *testing.T) {
+	for name, want := range map[string]bool{
+		"":                 true,
+		"@":                true,
+		"example.com":      true,
+		"Example.COM.":     true,
+		"www.example.com":  false,
+		"example.com.test": false,
+	} {
+		if got := isApexRecordName(name, "example.com"); got != want {
+			t.Errorf("isApexRecordName(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestResourceNameserverReadSignedZoneUnmanagedRecords(t *testing.T) {
+	stub := apitest.NewStub()
+	stub.Respond("nameserver.info", nameserverInfo(soaRecord,
+		map[string]interface{}{"id": 17, "name": "example.com", "type": "NS", "content": "ns.inwx.de"},
+		map[string]interface{}{"id": 18, "name": "example.com", "type": "DNSKEY", "content": "257 3 13 AwEAAa..."},
+		map[string]interface{}{"id": 19, "name": "example.com", "type": "RRSIG", "content": "SOA 13 2 3600 ..."},
+		map[string]interface{}{"id": 20, "name": "www.example.com", "type": "A", "content": "192.0.2.1"},
+		map[string]interface{}{"id": 21, "name": "mail.example.com", "type": "A", "content": "192.0.2.2"},
+	))
+
+	d := stateData(t, NameserverResource(), "example.com:1", nameserverState(map[string]interface{}{
+		"warn_unmanaged_records": true,
+		"managed_record_ids":     []interface{}{"example.com:20"},
+	}))
+	diags := resourceNameserverRead(context.Background(), d, stub.Client(t))
+	requireNoErrors(t, diags)
+
+	if len(diags) != 1 || diags[0].Summary != "Zone contains unmanaged records" {
+		t.Fatalf("expected one unmanaged records warning, got %v", diags)
+	}
+	if !strings.Contains(diags[0].Detail, "contains 1 records") || !strings.Contains(diags[0].Detail, "mail.example.com A 192.0.2.2") {
+		t.Errorf("expected only the unmanaged A record to be listed, got %s", diags[0].Detail)
+	}
+	for _, recordType := range []string{"DNSKEY", "RRSIG", "SOA"} {
+		if strings.Contains(diags[0].Detail, recordType) {
+			t.Errorf("expected no %s record to be listed, got %s", recordType, diags[0].Detail)
+		}
+	}
+}
+
+func TestResourceNameserverCreateProviderTesting(t *testing.T) {
+	stub := apitest.NewStub()
+	stub.Respond("nameserver.create", apitest.Success(map[string]interface{}{"roId": 1}))
+	stub.Respond("nameserver.info", apitest.Error(2303, "Object does not exist"))
+	client := stub.Client(t)
+	client.Testing = true
+
+	d := planData(t, NameserverResource(), "", nil, nameserverState(map[string]interface{}{"soa_refresh": 7200}), client)
+	requireNoErrors(t, resourceNameserverCreate(context.Background(), d, client))
+
+	if got := stub.LastCall("nameserver.create")["testing"]; got != true {
+		t.Errorf("expected the zone to be created in testing mode, got %v", got)
+	}
+	if calls := stub.Calls("nameserver.info"); len(calls) != 0 {
+		t.Errorf("expected the zone created in testing mode not to be read back, got %v", calls)
+	}
+	if d.Id() != "example.com:1" {
+		t.Errorf("expected the id to be kept after create in testing mode, got %q", d.Id())
+	}
+
+	// on the next refresh the zone does not exist and is planned again
+	requireNoErrors(t, resourceNameserverRead(context.Background(), d, client))
+	if d.Id() != "" {
+		t.Errorf("expected the zone to be removed from state on refresh, got %q", d.Id())
+	}
+}
@@ -0,0 +1,341 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/inwx/terraform-provider-inwx/inwx/internal/api"
+	"strconv"
+	"strings"
+)
+
+// UrlRedirectResource manages a URL record, which redirects a hostname of a zone to another url.
+func UrlRedirectResource() *schema.Resource {
+	validUrlRedirectTypes := []string{
+		"HEADER301", "HEADER302", "FRAME",
+	}
+
+	return &schema.Resource{
+		CreateContext: resourceUrlRedirectCreate,
+		ReadContext:   resourceUrlRedirectRead,
+		UpdateContext: resourceUrlRedirectUpdate,
+		DeleteContext: resourceUrlRedirectDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: func(ctx context.Context, d *schema.ResourceData, i interface{}) ([]*schema.ResourceData, error) {
+				domain, id, err := resourceNameserverRecordParseId(d.Id())
+				if err != nil {
+					return nil, err
+				}
+
+				d.Set("domain", domain)
+				d.SetId(fmt.Sprintf("%s:%s", domain, id))
+
+				return []*schema.ResourceData{d}, nil
+			},
+		},
+		Schema: map[string]*schema.Schema{
+			"domain": {
+				Description: "Domain name of the zone",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+			"source": {
+				Description:  "Redirected hostname, either relative to the zone or fully qualified. Empty for the zone apex",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "",
+				ForceNew:     true,
+				ValidateFunc: validation.Any(validation.StringIsEmpty, validation.StringMatch(recordNameRegexp, "must be a valid record name")),
+			},
+			"target": {
+				Description:  "Url the hostname is redirected to",
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.IsURLWithHTTPorHTTPS,
+			},
+			"type": {
+				Description:  "Type of the redirection. One of: " + strings.Join(validUrlRedirectTypes, ", "),
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "HEADER301",
+				ValidateFunc: validation.StringInSlice(validUrlRedirectTypes, false),
+			},
+			"title": {
+				Description: "Title of the frame redirection",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"description": {
+				Description: "Description of the frame redirection",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"keywords": {
+				Description: "Keywords of the frame redirection",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"fav_icon": {
+				Description: "FavIcon of the frame redirection",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"append_path": {
+				Description: "Append the requested path to the target",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			"ttl": {
+				Description:  "TTL (time to live) of the redirect record. Defaults to default_ttl of the provider",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Computed:     true,
+				ValidateFunc: validation.IntAtLeast(300),
+			},
+		},
+	}
+}
+
+func resourceUrlRedirectCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	client := m.(*api.Client)
+
+	domain := d.Get("domain").(string)
+
+	parameters := expandUrlRedirect(d)
+	parameters["domain"] = domain
+	parameters["type"] = "URL"
+	if source := d.Get("source").(string); source != "" {
+		parameters["name"] = source
+	}
+	if ttl, ok := d.GetOk("ttl"); ok {
+		parameters["ttl"] = ttl
+	} else {
+		parameters["ttl"] = client.DefaultTTL
+		d.Set("ttl", client.DefaultTTL)
+	}
+
+	call, err := client.Call(ctx, "nameserver.createRecord", parameters)
+	client.InvalidateNameserverInfo(domain)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not add url redirect",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	if !call.IsSuccess() {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not add url redirect",
+			Detail:   fmt.Sprintf("API response not status code 1000 or 1001. Got response: %s", call.ErrorMessage()),
+		})
+		return diags
+	}
+
+	resData, _ := call["resData"].(map[string]any)
+	id, ok := resData["id"].(float64)
+	if !ok {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not add url redirect",
+			Detail:   fmt.Sprintf("API response contains no id. Got response: %s", call.ApiError()),
+		})
+		return diags
+	}
+	d.SetId(domain + ":" + strconv.Itoa(int(id)))
+
+	if client.Testing {
+		return diags
+	}
+
+	return resourceUrlRedirectRead(ctx, d, m)
+}
+
+func resourceUrlRedirectRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	client := m.(*api.Client)
+
+	domain, id, err := resourceNameserverRecordParseId(d.Id())
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not parse id",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	call, err := client.NameserverInfo(ctx, domain)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not get nameserver info",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	if call.Code() != api.COMMAND_SUCCESSFUL {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not get nameserver info",
+			Detail:   fmt.Sprintf("API response not status code 1000. Got response: %s", call.ErrorMessage()),
+		})
+		return diags
+	}
+
+	resData, _ := call["resData"].(map[string]any)
+	records, _ := resData["record"].([]any)
+	for _, record := range records {
+		recordt, ok := record.(map[string]any)
+		if !ok {
+			continue
+		}
+		recordId, ok := recordt["id"].(float64)
+		if !ok || strconv.Itoa(int(recordId)) != id || recordt["type"] != "URL" {
+			continue
+		}
+
+		name, _ := recordt["name"].(string)
+		source := d.Get("source").(string)
+		if source == "" && isApexRecordName(name, domain) {
+			d.Set("source", "")
+		} else {
+			d.Set("source", flattenNameserverRecordName(source, name, domain))
+		}
+
+		if val, ok := recordt["content"].(string); ok {
+			d.Set("target", val)
+		}
+		if val, ok := recordt["urlRedirectType"].(string); ok {
+			d.Set("type", val)
+		}
+		if val, ok := recordt["urlRedirectTitle"].(string); ok {
+			d.Set("title", val)
+		}
+		if val, ok := recordt["urlRedirectDescription"].(string); ok {
+			d.Set("description", val)
+		}
+		if val, ok := recordt["urlRedirectKeywords"].(string); ok {
+			d.Set("keywords", val)
+		}
+		if val, ok := recordt["urlRedirectFavIcon"].(string); ok {
+			d.Set("fav_icon", val)
+		}
+		if val, ok := recordt["urlAppend"]; ok {
+			d.Set("append_path", api.IsTruthy(val))
+		}
+		if val, ok := recordt["ttl"].(float64); ok {
+			d.Set("ttl", int(val))
+		}
+
+		return diags
+	}
+
+	// If the redirect is not found, mark it as removed
+	d.SetId("")
+	return diags
+}
+
+func resourceUrlRedirectUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	client := m.(*api.Client)
+
+	domain, id, err := resourceNameserverRecordParseId(d.Id())
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not parse id",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	parameters := expandUrlRedirect(d)
+	parameters["id"] = id
+	if d.HasChange("ttl") {
+		parameters["ttl"] = d.Get("ttl").(int)
+	}
+
+	call, err := client.Call(ctx, "nameserver.updateRecord", parameters)
+	client.InvalidateNameserverInfo(domain)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not update url redirect",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	if !call.IsSuccess() {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not update url redirect",
+			Detail:   fmt.Sprintf("API response not status code 1000 or 1001. Got response: %s", call.ErrorMessage()),
+		})
+		return diags
+	}
+
+	if client.Testing {
+		return diags
+	}
+
+	return resourceUrlRedirectRead(ctx, d, m)
+}
+
+func resourceUrlRedirectDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	client := m.(*api.Client)
+
+	domain, id, err := resourceNameserverRecordParseId(d.Id())
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not parse id",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	call, err := client.Call(ctx, "nameserver.deleteRecord", map[string]interface{}{
+		"id": id,
+	})
+	client.InvalidateNameserverInfo(domain)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not delete url redirect",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	if !call.IsSuccess() {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not delete url redirect",
+			Detail:   fmt.Sprintf("API response not status code 1000 or 1001. Got response: %s", call.ErrorMessage()),
+		})
+		return diags
+	}
+
+	return diags
+}
+
+// expandUrlRedirect returns the redirect parameters of nameserver.createRecord and nameserver.updateRecord.
+// All of them are sent, so that removed optional fields are cleared.
+func expandUrlRedirect(d *schema.ResourceData) map[string]interface{} {
+	return map[string]interface{}{
+		"content":                d.Get("target").(string),
+		"urlRedirectType":        d.Get("type").(string),
+		"urlRedirectTitle":       d.Get("title").(string),
+		"urlRedirectDescription": d.Get("description").(string),
+		"urlRedirectKeywords":    d.Get("keywords").(string),
+		"urlRedirectFavIcon":     d.Get("fav_icon").(string),
+		"urlAppend":              d.Get("append_path").(bool),
+	}
+}
@@ -0,0 +1,450 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/inwx/terraform-provider-inwx/inwx/internal/api"
+	"strings"
+)
+
+func ZoneResource() *schema.Resource {
+	validRecordTypes := []string{
+		"A", "AAAA", "AFSDB", "ALIAS", "CAA", "CERT", "CNAME", "HINFO", "KEY", "LOC", "MX", "NAPTR", "NS", "OPENPGPKEY",
+		"PTR", "RP", "SMIMEA", "SRV", "SSHFP", "TLSA", "TXT", "URI", "URL",
+	}
+
+	return &schema.Resource{
+		CreateContext: resourceZoneCreate,
+		ReadContext:   resourceZoneRead,
+		UpdateContext: resourceZoneUpdate,
+		DeleteContext: resourceZoneDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceZoneImport,
+		},
+		Schema: map[string]*schema.Schema{
+			"domain": {
+				Description: "Domain name of the zone",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+			"record": {
+				Description: "Records of the zone. Records of the zone that are not listed are deleted, except for the SOA, apex NS and DNSSEC records. A zone with unlisted records has to be imported",
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Description: "Name of the record relative to the zone, empty for the zone apex",
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "",
+							ValidateFunc: validation.Any(
+								validation.StringIsEmpty,
+								validation.StringMatch(recordNameRegexp, "must be a valid record name"),
+							),
+						},
+						"type": {
+							Description:  "Type of the record. One of: " + strings.Join(validRecordTypes, ", "),
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.StringInSlice(validRecordTypes, false),
+						},
+						"content": {
+							Description: "Content of the record",
+							Type:        schema.TypeString,
+							Required:    true,
+						},
+						"ttl": {
+							Description: "TTL (time to live) of the record. New records without ttl get the default_ttl of the provider, existing records keep their ttl",
+							Type:        schema.TypeInt,
+							Optional:    true,
+						},
+						"prio": {
+							Description: "Priority of the record. Only used for MX, SRV and URI records",
+							Type:        schema.TypeInt,
+							Optional:    true,
+							Default:     0,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// resourceZoneImport imports all records of a zone by its domain name. The records are read by the following read
+// with a single nameserver.info call.
+func resourceZoneImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	client := m.(*api.Client)
+
+	domain := normalizeHostname(d.Id())
+	if domain == "" {
+		return nil, fmt.Errorf("unexpected format of ID (%s), expected the domain name of the zone", d.Id())
+	}
+
+	// fail the import instead of importing an empty zone, if the zone does not exist
+	if _, diags := zoneRecords(ctx, client, domain); diags.HasError() {
+		return nil, fmt.Errorf("could not import zone %s: %s", domain, diags[0].Detail)
+	}
+
+	if err := d.Set("domain", domain); err != nil {
+		return nil, err
+	}
+	d.SetId(domain)
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// resourceZoneCreate takes over the records of an existing zone. Records that are not configured are not deleted,
+// a zone with such records has to be imported instead, so that they are not removed by accident.
+func resourceZoneCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*api.Client)
+
+	domain := d.Get("domain").(string)
+
+	client.InvalidateNameserverInfo(domain)
+	records, diags := zoneRecords(ctx, client, domain)
+	if diags.HasError() {
+		return diags
+	}
+
+	configured := map[string]bool{}
+	for _, record := range d.Get("record").(*schema.Set).List() {
+		recordt := record.(map[string]interface{})
+		configured[zoneRecordKey(domain, recordt["type"].(string), recordt["name"].(string), recordt["content"].(string))] = true
+	}
+	var unconfigured []string
+	for _, record := range records {
+		recordType, _ := record["type"].(string)
+		name, _ := record["name"].(string)
+		content, _ := record["content"].(string)
+		if !configured[zoneRecordKey(domain, recordType, name, content)] {
+			unconfigured = append(unconfigured, fmt.Sprintf("%s %s %s", name, recordType, content))
+		}
+	}
+	if len(unconfigured) > 0 {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Zone contains records that are not configured",
+			Detail: fmt.Sprintf("The zone %s already contains records that are not configured: %s. Import the zone with "+
+				"`terraform import` to take over its records, or add them to the configuration.", domain, strings.Join(unconfigured, ", ")),
+		})
+		return diags
+	}
+
+	d.SetId(domain)
+
+	diags = reconcileZoneRecords(ctx, d, client)
+	if diags.HasError() {
+		return diags
+	}
+
+	if client.Testing {
+		return diags
+	}
+
+	return append(diags, resourceZoneRead(ctx, d, m)...)
+}
+
+func resourceZoneRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*api.Client)
+
+	domain := d.Get("domain").(string)
+
+	// the response is cached for zoneRecords
+	call, err := client.NameserverInfo(ctx, domain)
+	if err == nil && call.Code() == 2303 { // Object does not exist
+		d.SetId("")
+		return nil
+	}
+
+	records, diags := zoneRecords(ctx, client, domain)
+	if diags.HasError() {
+		return diags
+	}
+
+	// keep the configured form of names and contents, e.g. a relative name or a trailing dot
+	configured := map[string]map[string]interface{}{}
+	for _, record := range d.Get("record").(*schema.Set).List() {
+		recordt := record.(map[string]interface{})
+		configured[zoneRecordKey(domain, recordt["type"].(string), recordt["name"].(string), recordt["content"].(string))] = recordt
+	}
+
+	result := make([]interface{}, 0, len(records))
+	for _, record := range records {
+		recordType, _ := record["type"].(string)
+		name, _ := record["name"].(string)
+		content, _ := record["content"].(string)
+
+		item := map[string]interface{}{
+			"name":    relativeRecordName(domain, name),
+			"type":    recordType,
+			"content": content,
+		}
+		if recordt, ok := configured[zoneRecordKey(domain, recordType, name, content)]; ok {
+			item["name"] = recordt["name"]
+			item["content"] = recordt["content"]
+		}
+		if val, ok := record["ttl"].(float64); ok {
+			item["ttl"] = int(val)
+		}
+		// the ttl of records without configured ttl is not managed and kept as configured, so the records match
+		if recordt, ok := configured[zoneRecordKey(domain, recordType, name, content)]; ok && recordt["ttl"].(int) == 0 {
+			item["ttl"] = 0
+		}
+		if val, ok := record["prio"].(float64); ok {
+			item["prio"] = int(val)
+		}
+		result = append(result, item)
+	}
+
+	if err := d.Set("record", result); err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not set records",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	return diags
+}
+
+func resourceZoneUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*api.Client)
+
+	diags := reconcileZoneRecords(ctx, d, client)
+	if diags.HasError() {
+		return diags
+	}
+
+	if client.Testing {
+		return diags
+	}
+
+	return append(diags, resourceZoneRead(ctx, d, m)...)
+}
+
+// resourceZoneDelete deletes the records managed by the resource. The zone itself is managed by inwx_nameserver.
+func resourceZoneDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*api.Client)
+
+	domain := d.Get("domain").(string)
+
+	records, diags := zoneRecords(ctx, client, domain)
+	if diags.HasError() {
+		return diags
+	}
+
+	managed := map[string]bool{}
+	for _, record := range d.Get("record").(*schema.Set).List() {
+		recordt := record.(map[string]interface{})
+		managed[zoneRecordKey(domain, recordt["type"].(string), recordt["name"].(string), recordt["content"].(string))] = true
+	}
+
+	defer client.InvalidateNameserverInfo(domain)
+	for _, record := range records {
+		recordType, _ := record["type"].(string)
+		name, _ := record["name"].(string)
+		content, _ := record["content"].(string)
+		if !managed[zoneRecordKey(domain, recordType, name, content)] {
+			continue
+		}
+
+		diags = append(diags, zoneRecordCall(ctx, client, "nameserver.deleteRecord", map[string]interface{}{
+			"id": record["id"],
+		}, "Could not delete nameserver record")...)
+		if diags.HasError() {
+			return diags
+		}
+	}
+
+	return diags
+}
+
+// reconcileZoneRecords changes the records of the zone to the configured ones with a single nameserver.info call.
+// Records are matched by name, type and content, records that only differ in ttl or prio are updated in place.
+func reconcileZoneRecords(ctx context.Context, d *schema.ResourceData, client *api.Client) diag.Diagnostics {
+	domain := d.Get("domain").(string)
+
+	client.InvalidateNameserverInfo(domain)
+	records, diags := zoneRecords(ctx, client, domain)
+	if diags.HasError() {
+		return diags
+	}
+	defer client.InvalidateNameserverInfo(domain)
+
+	current := map[string][]map[string]any{}
+	for _, record := range records {
+		recordType, _ := record["type"].(string)
+		name, _ := record["name"].(string)
+		content, _ := record["content"].(string)
+		key := zoneRecordKey(domain, recordType, name, content)
+		current[key] = append(current[key], record)
+	}
+
+	var updates []map[string]interface{}
+	var creates []map[string]interface{}
+	for _, record := range d.Get("record").(*schema.Set).List() {
+		recordt := record.(map[string]interface{})
+		key := zoneRecordKey(domain, recordt["type"].(string), recordt["name"].(string), recordt["content"].(string))
+
+		// records without configured ttl are created with the default ttl and keep their ttl otherwise
+		ttl := recordt["ttl"].(int)
+
+		existing := current[key]
+		if len(existing) == 0 {
+			if ttl == 0 {
+				ttl = client.DefaultTTL
+			}
+			parameters := map[string]interface{}{
+				"domain":  domain,
+				"type":    recordt["type"],
+				"content": recordt["content"],
+				"ttl":     ttl,
+			}
+			if prioRecordTypes[recordt["type"].(string)] {
+				parameters["prio"] = recordt["prio"]
+			}
+			if name := recordt["name"].(string); name != "" {
+				parameters["name"] = name
+			}
+			creates = append(creates, parameters)
+			continue
+		}
+		current[key] = existing[1:]
+
+		existingTtl, _ := existing[0]["ttl"].(float64)
+		if ttl == 0 {
+			ttl = int(existingTtl)
+		}
+		prio, _ := existing[0]["prio"].(float64)
+		prioChanged := prioRecordTypes[recordt["type"].(string)] && int(prio) != recordt["prio"].(int)
+		if int(existingTtl) != ttl || prioChanged {
+			parameters := map[string]interface{}{
+				"id":  existing[0]["id"],
+				"ttl": ttl,
+			}
+			if prioRecordTypes[recordt["type"].(string)] {
+				parameters["prio"] = recordt["prio"]
+			}
+			updates = append(updates, parameters)
+		}
+	}
+
+	// delete first, so that new records do not conflict with records that are replaced, e.g. a CNAME by an A record
+	for _, existing := range current {
+		for _, record := range existing {
+			diags = append(diags, zoneRecordCall(ctx, client, "nameserver.deleteRecord", map[string]interface{}{
+				"id": record["id"],
+			}, "Could not delete nameserver record")...)
+			if diags.HasError() {
+				return diags
+			}
+		}
+	}
+	for _, parameters := range updates {
+		diags = append(diags, zoneRecordCall(ctx, client, "nameserver.updateRecord", parameters, "Could not update nameserver record")...)
+		if diags.HasError() {
+			return diags
+		}
+	}
+	for _, parameters := range creates {
+		diags = append(diags, zoneRecordCall(ctx, client, "nameserver.createRecord", parameters, "Could not add nameserver record")...)
+		if diags.HasError() {
+			return diags
+		}
+	}
+
+	return diags
+}
+
+// zoneRecords returns the records of a zone which can be managed by inwx_zone. The SOA, apex NS and DNSSEC records
+// belong to the zone itself.
+func zoneRecords(ctx context.Context, client *api.Client, domain string) ([]map[string]any, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	call, err := client.NameserverInfo(ctx, domain)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not get nameserver info",
+			Detail:   err.Error(),
+		})
+		return nil, diags
+	}
+	if call.Code() != api.COMMAND_SUCCESSFUL {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not get nameserver info",
+			Detail:   fmt.Sprintf("API response not status code 1000. Got response: %s", call.ErrorMessage()),
+		})
+		return nil, diags
+	}
+
+	resData, _ := call["resData"].(map[string]any)
+	records, _ := resData["record"].([]any)
+
+	result := make([]map[string]any, 0, len(records))
+	for _, record := range records {
+		recordt, ok := record.(map[string]any)
+		if !ok {
+			continue
+		}
+		recordType, _ := recordt["type"].(string)
+		name, _ := recordt["name"].(string)
+		if recordType == "SOA" || (recordType == "NS" && isApexRecordName(name, domain)) || dnssecRecordTypes[recordType] {
+			continue
+		}
+		result = append(result, recordt)
+	}
+
+	return result, diags
+}
+
+// zoneRecordCall calls a record method of the api and converts errors to diagnostics.
+func zoneRecordCall(ctx context.Context, client *api.Client, method string, parameters map[string]interface{}, summary string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	call, err := client.Call(ctx, method, parameters)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  summary,
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	if !call.IsSuccess() {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  summary,
+			Detail:   fmt.Sprintf("API response not status code 1000 or 1001. Got response: %s", call.ErrorMessage()),
+		})
+		return diags
+	}
+
+	return diags
+}
+
+// zoneRecordKey identifies a record by type, fully qualified name and content. Hostnames in the content are
+// compared without case and trailing dot.
+func zoneRecordKey(domain string, recordType string, name string, content string) string {
+	if hostnameRecordTypes[recordType] {
+		content = normalizeHostname(content)
+	}
+	return fmt.Sprintf("%s %s %s", recordType, plannedRecordKey(domain, name), content)
+}
+
+// relativeRecordName converts a record name returned by the api to the name relative to the zone.
+func relativeRecordName(domain string, name string) string {
+	name = plannedRecordKey(domain, name)
+	if name == normalizeHostname(domain) {
+		return ""
+	}
+	return strings.TrimSuffix(name, "."+normalizeHostname(domain))
+}
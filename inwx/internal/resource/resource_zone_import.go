@@ -0,0 +1,192 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/inwx/terraform-provider-inwx/inwx/internal/api"
+	"github.com/inwx/terraform-provider-inwx/inwx/internal/zonefile"
+	"strconv"
+)
+
+func ZoneImportResource() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceZoneImportCreate,
+		ReadContext:   resourceZoneImportRead,
+		DeleteContext: resourceZoneImportDelete,
+		CustomizeDiff: resourceZoneImportCustomizeDiff,
+		Schema: map[string]*schema.Schema{
+			"domain": {
+				Description: "Domain name of the zone, used as origin of the zone file",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+			"bind_zonefile": {
+				Description: "Contents of the zone file in BIND (RFC 1035 master file) format",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+			"record_ids": {
+				Description: "Ids of the records created from the zone file",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+		},
+	}
+}
+
+// resourceZoneImportCustomizeDiff parses the zone file during plan, so that errors in it are reported before any
+// record is created.
+func resourceZoneImportCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !d.NewValueKnown("domain") || !d.NewValueKnown("bind_zonefile") {
+		return nil
+	}
+
+	// the TTL does not matter for the validation
+	_, err := zoneImportRecords(d.Get("bind_zonefile").(string), normalizeHostname(d.Get("domain").(string)), 3600)
+	return err
+}
+
+// zoneImportRecords parses a zone file and returns the records to create. The SOA, apex NS and DNSSEC records belong
+// to the zone itself and are skipped.
+func zoneImportRecords(content string, domain string, defaultTTL int) ([]zonefile.Record, error) {
+	records, err := zonefile.Parse(content, domain, defaultTTL)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse zone file: %w", err)
+	}
+
+	result := make([]zonefile.Record, 0, len(records))
+	for _, record := range records {
+		if record.Type == "SOA" || (record.Type == "NS" && isApexRecordName(record.Name, domain)) || dnssecRecordTypes[record.Type] {
+			continue
+		}
+		if plannedRecordKey(domain, record.Name) != normalizeHostname(record.Name) {
+			return nil, fmt.Errorf("the record %s %s of the zone file is not part of the zone %s", record.Name, record.Type, domain)
+		}
+		result = append(result, record)
+	}
+
+	return result, nil
+}
+
+func resourceZoneImportCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	client := m.(*api.Client)
+
+	domain := normalizeHostname(d.Get("domain").(string))
+
+	records, err := zoneImportRecords(d.Get("bind_zonefile").(string), domain, client.DefaultTTL)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Could not import zone file",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	d.SetId(domain)
+	defer client.InvalidateNameserverInfo(domain)
+
+	// record_ids is set after each record, so that records of a partially failed import are deleted with the resource
+	var recordIds []string
+	for _, record := range records {
+		parameters := map[string]interface{}{
+			"domain":  domain,
+			"name":    record.Name,
+			"type":    record.Type,
+			"content": record.Content,
+			"ttl":     record.TTL,
+		}
+		if prioRecordTypes[record.Type] {
+			parameters["prio"] = record.Prio
+		}
+
+		call, err := client.Call(ctx, "nameserver.createRecord", parameters)
+		if err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Could not add nameserver record",
+				Detail:   err.Error(),
+			})
+			return diags
+		}
+		if !call.IsSuccess() {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Could not add nameserver record",
+				Detail: fmt.Sprintf("API response not status code 1000 or 1001 for the record %s %s %s. Got response: %s",
+					record.Name, record.Type, record.Content, call.ErrorMessage()),
+			})
+			return diags
+		}
+
+		resData, _ := call["resData"].(map[string]any)
+		if id, ok := resData["id"].(float64); ok {
+			recordIds = append(recordIds, strconv.Itoa(int(id)))
+			d.Set("record_ids", recordIds)
+		}
+	}
+
+	if client.Testing {
+		return diags
+	}
+
+	return append(diags, resourceZoneImportRead(ctx, d, m)...)
+}
+
+// resourceZoneImportRead removes records that were deleted outside of terraform from record_ids.
+func resourceZoneImportRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*api.Client)
+
+	records, diags := zoneRecords(ctx, client, d.Get("domain").(string))
+	if diags.HasError() {
+		return diags
+	}
+
+	existing := map[string]bool{}
+	for _, record := range records {
+		if id, ok := record["id"].(float64); ok {
+			existing[strconv.Itoa(int(id))] = true
+		}
+	}
+
+	recordIds := []string{}
+	for _, id := range d.Get("record_ids").([]interface{}) {
+		if existing[id.(string)] {
+			recordIds = append(recordIds, id.(string))
+		}
+	}
+	d.Set("record_ids", recordIds)
+
+	return diags
+}
+
+// resourceZoneImportDelete deletes the records created from the zone file.
+func resourceZoneImportDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	client := m.(*api.Client)
+
+	defer client.InvalidateNameserverInfo(d.Get("domain").(string))
+	for _, id := range d.Get("record_ids").([]interface{}) {
+		recordId, err := strconv.Atoi(id.(string))
+		if err != nil {
+			continue
+		}
+
+		diags = append(diags, zoneRecordCall(ctx, client, "nameserver.deleteRecord", map[string]interface{}{
+			"id": recordId,
+		}, "Could not delete nameserver record")...)
+		if diags.HasError() {
+			return diags
+		}
+	}
+
+	return diags
+}
@@ -0,0 +1,57 @@
+package resource
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/inwx/terraform-provider-inwx/inwx/internal/api/apitest"
+)
+
+func TestResourceZoneImportPlanInvalidZoneFile(t *testing.T) {
+	tests := []struct {
+		name      string
+		zoneFile  string
+		wantError string
+	}{
+		{name: "syntax error", zoneFile: "www A 192.0.2.1 )\n", wantError: "unexpected )"},
+		{name: "foreign record", zoneFile: "www.example.net. A 192.0.2.1\n", wantError: "not part of the zone example.com"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, _, err := diffResource(t, ZoneImportResource(), "", nil, map[string]interface{}{
+				"domain":        "example.com",
+				"bind_zonefile": test.zoneFile,
+			}, nil)
+			if err == nil || !strings.Contains(err.Error(), test.wantError) {
+				t.Errorf("expected plan error containing %q, got %v", test.wantError, err)
+			}
+		})
+	}
+}
+
+func TestResourceZoneImportCreateSkipsZoneRecords(t *testing.T) {
+	stub := apitest.NewStub()
+	stub.Respond("nameserver.createRecord", apitest.Success(map[string]interface{}{"id": 101}))
+	stub.Respond("nameserver.info", nameserverInfo())
+	client := stub.Client(t)
+
+	d := planData(t, ZoneImportResource(), "", nil, map[string]interface{}{
+		"domain": "example.com",
+		"bind_zonefile": `@ SOA ns.inwx.de. hostmaster.example.com. 1 10800 3600 604800 3600
+@ NS ns.inwx.de.
+@ DNSKEY 257 3 13 mdsswUyr3DPW132mOi8V9xESWE8jTo0dxCjjnopKl+GqJxpVXckHAeF+KkxLbxILfDLUT0rAK9iUzy1L53eKGQ==
+@ RRSIG DNSKEY 13 2 3600 20240201000000 20240101000000 12345 example.com. (
+	c2lnbmF0dXJl )
+@ NSEC www.example.com. A NS SOA RRSIG NSEC DNSKEY
+www A 192.0.2.1
+`,
+	}, client)
+	requireNoErrors(t, resourceZoneImportCreate(context.Background(), d, client))
+
+	calls := stub.Calls("nameserver.createRecord")
+	if len(calls) != 1 || calls[0].Params["type"] != "A" || calls[0].Params["name"] != "www.example.com" {
+		t.Errorf("expected only the A record to be created, got %v", calls)
+	}
+}
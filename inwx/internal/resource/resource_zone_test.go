@@ -0,0 +1,149 @@
+package resource
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/inwx/terraform-provider-inwx/inwx/internal/api/apitest"
+)
+
+// zoneConfig returns the configuration of the zone example.com with records.
+func zoneConfig(records ...map[string]interface{}) map[string]interface{} {
+	recordList := make([]interface{}, len(records))
+	for i, record := range records {
+		recordList[i] = record
+	}
+	return map[string]interface{}{
+		"domain": "example.com",
+		"record": recordList,
+	}
+}
+
+func TestResourceZoneCreate(t *testing.T) {
+	stub := apitest.NewStub()
+	stub.Respond("nameserver.info", nameserverInfo(
+		soaRecord,
+		map[string]interface{}{"id": 17, "name": "example.com", "type": "NS", "content": "ns.inwx.de", "ttl": 86400},
+		map[string]interface{}{"id": 18, "name": "www.example.com", "type": "A", "content": "192.0.2.1", "ttl": 3600},
+	))
+	stub.Respond("nameserver.createRecord", apitest.Success(map[string]interface{}{"id": 19}))
+	client := stub.Client(t)
+
+	d := planData(t, ZoneResource(), "", nil, zoneConfig(
+		map[string]interface{}{"name": "www", "type": "A", "content": "192.0.2.1"},
+		map[string]interface{}{"type": "MX", "content": "mail.example.com", "prio": 10},
+	), client)
+	requireNoErrors(t, resourceZoneCreate(context.Background(), d, client))
+
+	if d.Id() != "example.com" {
+		t.Errorf("expected id example.com, got %s", d.Id())
+	}
+	if calls := stub.Calls("nameserver.deleteRecord"); len(calls) != 0 {
+		t.Errorf("expected no records to be deleted, got %v", calls)
+	}
+	params := stub.LastCall("nameserver.createRecord")
+	if params["type"] != "MX" || params["content"] != "mail.example.com" || params["prio"] != float64(10) {
+		t.Errorf("unexpected nameserver.createRecord parameters %v", params)
+	}
+}
+
+func TestResourceZoneDefaultTTL(t *testing.T) {
+	stub := apitest.NewStub()
+	stub.Respond("nameserver.info", nameserverInfo(
+		soaRecord,
+		map[string]interface{}{"id": 18, "name": "www.example.com", "type": "A", "content": "192.0.2.1", "ttl": 300},
+	))
+	stub.Respond("nameserver.createRecord", apitest.Success(map[string]interface{}{"id": 19}))
+	client := stub.Client(t)
+	client.DefaultTTL = 600
+
+	config := zoneConfig(
+		map[string]interface{}{"name": "www", "type": "A", "content": "192.0.2.1"},
+		map[string]interface{}{"name": "mail", "type": "A", "content": "192.0.2.2"},
+	)
+	d := planData(t, ZoneResource(), "", nil, config, client)
+	requireNoErrors(t, resourceZoneCreate(context.Background(), d, client))
+
+	if params := stub.LastCall("nameserver.createRecord"); params["ttl"] != float64(600) {
+		t.Errorf("expected the default TTL of the provider for a new record, got %v", params)
+	}
+	if calls := stub.Calls("nameserver.updateRecord"); len(calls) != 0 {
+		t.Errorf("expected the TTL of the existing record to be kept, got %v", calls)
+	}
+
+	requireNoErrors(t, resourceZoneRead(context.Background(), d, client))
+	for _, record := range d.Get("record").(*schema.Set).List() {
+		if record := record.(map[string]interface{}); record["name"] == "www" && record["ttl"] != 0 {
+			t.Errorf("expected the unmanaged TTL not to be read, got %v", record)
+		}
+	}
+
+	state := map[string]interface{}{
+		"domain": "example.com",
+		"record": []interface{}{
+			map[string]interface{}{"name": "www", "type": "A", "content": "192.0.2.1", "ttl": 0, "prio": 0},
+			map[string]interface{}{"name": "mail", "type": "A", "content": "192.0.2.2", "ttl": 0, "prio": 0},
+		},
+	}
+	_, diff, err := diffResource(t, ZoneResource(), "example.com", state, config, client)
+	if err != nil {
+		t.Fatalf("could not plan: %v", err)
+	}
+	if diff != nil && len(diff.Attributes) > 0 {
+		t.Errorf("expected no diff for records without configured ttl, got %v", diff.Attributes)
+	}
+
+	config["record"].([]interface{})[0].(map[string]interface{})["ttl"] = 900
+	_, diff, err = diffResource(t, ZoneResource(), "example.com", state, config, client)
+	if err != nil {
+		t.Fatalf("could not plan: %v", err)
+	}
+	changed := false
+	if diff != nil {
+		for key, attribute := range diff.Attributes {
+			if strings.HasSuffix(key, ".ttl") && attribute.New == "900" {
+				changed = true
+			}
+		}
+	}
+	if !changed {
+		t.Errorf("expected a ttl change of the record, got %v", diff)
+	}
+}
+
+func TestResourceZoneCreateUnconfiguredRecords(t *testing.T) {
+	stub := apitest.NewStub()
+	stub.Respond("nameserver.info", nameserverInfo(
+		soaRecord,
+		map[string]interface{}{"id": 18, "name": "www.example.com", "type": "A", "content": "192.0.2.1", "ttl": 3600},
+	))
+	client := stub.Client(t)
+
+	d := planData(t, ZoneResource(), "", nil, zoneConfig(
+		map[string]interface{}{"type": "A", "content": "192.0.2.2"},
+	), client)
+	diags := resourceZoneCreate(context.Background(), d, client)
+	if !diags.HasError() || !strings.Contains(diags[0].Detail, "www.example.com A 192.0.2.1") {
+		t.Fatalf("expected error about the unconfigured record, got %v", diags)
+	}
+	if d.Id() != "" {
+		t.Errorf("expected no id after a refused create, got %s", d.Id())
+	}
+	if calls := append(stub.Calls("nameserver.deleteRecord"), stub.Calls("nameserver.createRecord")...); len(calls) != 0 {
+		t.Errorf("expected the zone to be unchanged, got %v", calls)
+	}
+}
+
+func TestResourceZoneReadRemoved(t *testing.T) {
+	stub := apitest.NewStub()
+	stub.Respond("nameserver.info", apitest.Error(2303, "Object does not exist"))
+
+	d := stateData(t, ZoneResource(), "example.com", map[string]interface{}{"domain": "example.com"})
+	requireNoErrors(t, resourceZoneRead(context.Background(), d, stub.Client(t)))
+
+	if d.Id() != "" {
+		t.Errorf("expected a removed zone to be removed from state, got id %s", d.Id())
+	}
+}
@@ -0,0 +1,378 @@
+package zonefile
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Record is a single resource record of a zone as used by the INWX api. Name is fully qualified without trailing
+// dot, the priority of MX, SRV and URI records is kept in Prio instead of the content.
+type Record struct {
+	Name    string
+	Type    string
+	Content string
+	TTL     int
+	Prio    int
+}
+
+// hostnameTypes are the record types whose content ends with a hostname.
+var hostnameTypes = map[string]bool{
+	"CNAME": true,
+	"MX":    true,
+	"NS":    true,
+	"PTR":   true,
+	"SRV":   true,
+}
+
+// prioTypes are the record types whose rdata starts with the priority.
+var prioTypes = map[string]bool{
+	"MX":  true,
+	"SRV": true,
+	"URI": true,
+}
+
+// nonStandardTypes are INWX specific record types which have no representation in a master file.
+var nonStandardTypes = map[string]bool{
+	"ALIAS": true,
+	"URL":   true,
+}
+
+// txtSegmentLength is the maximum length of a single character-string in a TXT record
+const txtSegmentLength = 255
+
+// Format returns the records of a zone in RFC 1035 master file format. The SOA record is written first, followed by
+// the other records sorted by name and type. Records of INWX specific types are written as comments.
+func Format(origin string, defaultTTL int, records []Record) string {
+	origin = fqdn(origin)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "$ORIGIN %s\n", origin)
+	fmt.Fprintf(&b, "$TTL %d\n", defaultTTL)
+
+	sorted := make([]Record, len(records))
+	copy(sorted, records)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if (sorted[i].Type == "SOA") != (sorted[j].Type == "SOA") {
+			return sorted[i].Type == "SOA"
+		}
+		if sorted[i].Name != sorted[j].Name {
+			return sorted[i].Name < sorted[j].Name
+		}
+		return sorted[i].Type < sorted[j].Type
+	})
+
+	for _, record := range sorted {
+		line := fmt.Sprintf("%s\t%d\tIN\t%s\t%s", fqdn(record.Name), record.TTL, record.Type, formatContent(record))
+		if nonStandardTypes[record.Type] {
+			line = "; " + line
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// formatContent returns the rdata of a record in master file format.
+func formatContent(record Record) string {
+	content := record.Content
+
+	switch record.Type {
+	case "SOA":
+		// <mname> <rname> <serial> <refresh> <retry> <expire> <minimum>
+		parts := strings.Fields(content)
+		if len(parts) >= 2 {
+			parts[0] = fqdn(parts[0])
+			parts[1] = fqdn(parts[1])
+		}
+		return strings.Join(parts, " ")
+	case "TXT", "SPF":
+		return formatTxt(content)
+	}
+
+	if hostnameTypes[record.Type] {
+		parts := strings.Fields(content)
+		if len(parts) > 0 {
+			parts[len(parts)-1] = fqdn(parts[len(parts)-1])
+		}
+		content = strings.Join(parts, " ")
+	}
+	if prioTypes[record.Type] {
+		content = fmt.Sprintf("%d %s", record.Prio, content)
+	}
+
+	return content
+}
+
+// formatTxt quotes TXT content and splits it into character-strings of at most 255 bytes, without splitting a
+// multi-byte character. Content that is already quoted is kept as it is.
+func formatTxt(content string) string {
+	if strings.HasPrefix(content, "\"") {
+		return content
+	}
+
+	escape := strings.NewReplacer("\\", "\\\\", "\"", "\\\"")
+
+	var segments []string
+	for len(content) > txtSegmentLength {
+		end := txtSegmentLength
+		for end > 0 && !utf8.RuneStart(content[end]) {
+			end--
+		}
+		segments = append(segments, "\""+escape.Replace(content[:end])+"\"")
+		content = content[end:]
+	}
+	segments = append(segments, "\""+escape.Replace(content)+"\"")
+
+	return strings.Join(segments, " ")
+}
+
+// fqdn adds the trailing dot to a hostname.
+func fqdn(name string) string {
+	if strings.HasSuffix(name, ".") {
+		return name
+	}
+	return name + "."
+}
+
+// Parse parses a zone file in RFC 1035 master file format. Relative names are completed with the origin, which
+// can be changed with $ORIGIN. Records without TTL use the TTL of $TTL or defaultTTL. The priority of MX, SRV and URI
+// records is moved from the content to Prio and TXT character-strings are joined to the plain text.
+func Parse(content string, origin string, defaultTTL int) ([]Record, error) {
+	origin = fqdn(strings.ToLower(origin))
+	ttl := defaultTTL
+	owner := origin
+
+	lines, err := tokenize(content)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []Record
+	for _, line := range lines {
+		fields := line.fields
+
+		switch strings.ToUpper(fields[0].value) {
+		case "$ORIGIN":
+			if len(fields) != 2 {
+				return nil, fmt.Errorf("line %d: $ORIGIN expects a domain name", line.number)
+			}
+			origin = qualify(fields[1].value, origin)
+			continue
+		case "$TTL":
+			if len(fields) != 2 {
+				return nil, fmt.Errorf("line %d: $TTL expects a TTL", line.number)
+			}
+			if ttl, err = parseTTL(fields[1].value); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line.number, err)
+			}
+			continue
+		case "$INCLUDE", "$GENERATE":
+			return nil, fmt.Errorf("line %d: %s is not supported", line.number, fields[0].value)
+		}
+
+		// a line starting with whitespace belongs to the previous owner
+		if !line.continued {
+			owner = qualify(fields[0].value, origin)
+			fields = fields[1:]
+		}
+
+		record := Record{
+			Name: strings.TrimSuffix(owner, "."),
+			TTL:  ttl,
+		}
+
+		// TTL and class are optional and may appear in any order before the type
+		for len(fields) > 0 {
+			if recordTTL, err := parseTTL(fields[0].value); err == nil {
+				record.TTL = recordTTL
+			} else if upper := strings.ToUpper(fields[0].value); upper != "IN" && upper != "CH" && upper != "HS" {
+				break
+			}
+			fields = fields[1:]
+		}
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("line %d: expected a record type and data", line.number)
+		}
+		record.Type = strings.ToUpper(fields[0].value)
+		data := fields[1:]
+
+		switch record.Type {
+		case "MX", "SRV", "URI":
+			prio, err := strconv.Atoi(data[0].value)
+			if err != nil || len(data) < 2 {
+				return nil, fmt.Errorf("line %d: %s record expects a priority followed by the data", line.number, record.Type)
+			}
+			record.Prio = prio
+			data = data[1:]
+		case "TXT", "SPF":
+			var text strings.Builder
+			for _, field := range data {
+				text.WriteString(field.value)
+			}
+			record.Content = text.String()
+			records = append(records, record)
+			continue
+		}
+
+		values := make([]string, len(data))
+		for i, field := range data {
+			values[i] = field.raw
+		}
+		if hostnameTypes[record.Type] {
+			last := len(values) - 1
+			values[last] = strings.TrimSuffix(qualify(values[last], origin), ".")
+		}
+		record.Content = strings.Join(values, " ")
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// field is a single field of a line in a zone file. value is unquoted and unescaped, raw is the field as written.
+type field struct {
+	value string
+	raw   string
+}
+
+// line is a logical line of a zone file, lines in parentheses are joined.
+type line struct {
+	number    int
+	continued bool
+	fields    []field
+}
+
+// tokenize splits a zone file into logical lines of fields. Comments are removed and lines in parentheses joined.
+func tokenize(content string) ([]line, error) {
+	var lines []line
+	var current line
+	var value, raw strings.Builder
+	inField, quoted, parentheses := false, false, 0
+	number := 1
+
+	endField := func() {
+		if inField {
+			current.fields = append(current.fields, field{value: value.String(), raw: raw.String()})
+			value.Reset()
+			raw.Reset()
+			inField = false
+		}
+	}
+	endLine := func() {
+		endField()
+		if len(current.fields) > 0 {
+			lines = append(lines, current)
+		}
+		current = line{}
+	}
+
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+
+		if current.number == 0 {
+			current.number = number
+			current.continued = c == ' ' || c == '\t'
+		}
+
+		switch {
+		case c == '\\' && i+1 < len(content):
+			i++
+			value.WriteByte(content[i])
+			raw.WriteByte(c)
+			raw.WriteByte(content[i])
+			inField = true
+		case quoted:
+			if c == '"' {
+				quoted = false
+			} else {
+				value.WriteByte(c)
+			}
+			if c == '\n' {
+				number++
+			}
+			raw.WriteByte(c)
+		case c == '"':
+			quoted = true
+			inField = true
+			raw.WriteByte(c)
+		case c == ';':
+			for i+1 < len(content) && content[i+1] != '\n' {
+				i++
+			}
+		case c == '(':
+			endField()
+			parentheses++
+		case c == ')':
+			endField()
+			if parentheses == 0 {
+				return nil, fmt.Errorf("line %d: unexpected )", number)
+			}
+			parentheses--
+		case c == '\n':
+			number++
+			if parentheses > 0 {
+				endField()
+			} else {
+				endLine()
+			}
+		case c == ' ' || c == '\t' || c == '\r':
+			endField()
+		default:
+			value.WriteByte(c)
+			raw.WriteByte(c)
+			inField = true
+		}
+	}
+
+	if quoted {
+		return nil, fmt.Errorf("line %d: unterminated quoted string", number)
+	}
+	if parentheses > 0 {
+		return nil, fmt.Errorf("line %d: unterminated (", number)
+	}
+	endLine()
+
+	return lines, nil
+}
+
+// qualify returns the fully qualified name with trailing dot of a name relative to the origin.
+func qualify(name string, origin string) string {
+	name = strings.ToLower(name)
+	if name == "@" {
+		return origin
+	}
+	if strings.HasSuffix(name, ".") {
+		return name
+	}
+	return name + "." + origin
+}
+
+// parseTTL parses a TTL in seconds or with the units s, m, h, d and w, e.g. 1h30m.
+func parseTTL(ttl string) (int, error) {
+	units := map[byte]int{'s': 1, 'm': 60, 'h': 3600, 'd': 86400, 'w': 604800}
+
+	total, number, hasNumber := 0, 0, false
+	for i := 0; i < len(ttl); i++ {
+		c := ttl[i]
+		switch {
+		case c >= '0' && c <= '9':
+			number = number*10 + int(c-'0')
+			hasNumber = true
+		case hasNumber && units[c|0x20] > 0:
+			total += number * units[c|0x20]
+			number, hasNumber = 0, false
+		default:
+			return 0, fmt.Errorf("invalid TTL %s", ttl)
+		}
+	}
+	if ttl == "" || (!hasNumber && total == 0) {
+		return 0, fmt.Errorf("invalid TTL %s", ttl)
+	}
+
+	return total + number, nil
+}
@@ -0,0 +1,175 @@
+package zonefile
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestParse(t *testing.T) {
+	content := `$TTL 1h
+@	IN	SOA	ns.inwx.de. hostmaster.example.com. (
+		2024010101 ; serial
+		10800      ; refresh
+		3600       ; retry
+		604800     ; expire
+		3600 )     ; minimum
+	IN	NS	ns.inwx.de.
+www	300	IN	A	192.0.2.1
+	IN	AAAA	2001:db8::1
+@	MX	10 mail
+_sip._tcp	SRV	20 5 5060 sip.example.net.
+txt	TXT	"v=spf1 include:example.net -all" "second \"part\""
+$ORIGIN sub.example.com.
+host	CNAME	www.example.com.
+$TTL 600
+mail	A	192.0.2.2
+`
+
+	records, err := Parse(content, "Example.com", 3600)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []Record{
+		{Name: "example.com", Type: "SOA", Content: "ns.inwx.de. hostmaster.example.com. 2024010101 10800 3600 604800 3600", TTL: 3600},
+		{Name: "example.com", Type: "NS", Content: "ns.inwx.de", TTL: 3600},
+		{Name: "www.example.com", Type: "A", Content: "192.0.2.1", TTL: 300},
+		{Name: "www.example.com", Type: "AAAA", Content: "2001:db8::1", TTL: 3600},
+		{Name: "example.com", Type: "MX", Content: "mail.example.com", TTL: 3600, Prio: 10},
+		{Name: "_sip._tcp.example.com", Type: "SRV", Content: "5 5060 sip.example.net", TTL: 3600, Prio: 20},
+		{Name: "txt.example.com", Type: "TXT", Content: `v=spf1 include:example.net -allsecond "part"`, TTL: 3600},
+		{Name: "host.sub.example.com", Type: "CNAME", Content: "www.example.com", TTL: 3600},
+		{Name: "mail.sub.example.com", Type: "A", Content: "192.0.2.2", TTL: 600},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("unexpected records\n got: %+v\nwant: %+v", records, want)
+	}
+}
+
+func TestParseDefaultTTL(t *testing.T) {
+	records, err := Parse("www A 192.0.2.1\n", "example.com", 7200)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(records) != 1 || records[0].TTL != 7200 {
+		t.Errorf("expected the default TTL without $TTL, got %+v", records)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		wantError string
+	}{
+		{name: "unterminated parentheses", content: "@ SOA ns.inwx.de. hostmaster.example.com. ( 1 2 3 4 5\n", wantError: "unterminated ("},
+		{name: "unexpected parenthesis", content: "www A 192.0.2.1 )\n", wantError: "unexpected )"},
+		{name: "unterminated quote", content: "txt TXT \"text\n", wantError: "unterminated quoted string"},
+		{name: "include", content: "$INCLUDE other.zone\n", wantError: "$INCLUDE is not supported"},
+		{name: "invalid ttl", content: "$TTL 1x\n", wantError: "invalid TTL 1x"},
+		{name: "missing data", content: "www A\n", wantError: "line 1: expected a record type and data"},
+		{name: "mx without priority", content: "@ MX mail.example.com.\n", wantError: "MX record expects a priority"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Parse(test.content, "example.com", 3600)
+			if err == nil || !strings.Contains(err.Error(), test.wantError) {
+				t.Errorf("expected error containing %q, got %v", test.wantError, err)
+			}
+		})
+	}
+}
+
+func TestParseTTL(t *testing.T) {
+	for ttl, want := range map[string]int{"3600": 3600, "1h": 3600, "1h30m": 5400, "1W": 604800, "2d": 172800} {
+		if got, err := parseTTL(ttl); err != nil || got != want {
+			t.Errorf("parseTTL(%q) = %d, %v, want %d", ttl, got, err, want)
+		}
+	}
+}
+
+func TestFormatRoundTrip(t *testing.T) {
+	records := []Record{
+		{Name: "www.example.com", Type: "A", Content: "192.0.2.1", TTL: 300},
+		{Name: "example.com", Type: "SOA", Content: "ns.inwx.de hostmaster.example.com 2024010101 10800 3600 604800 3600", TTL: 86400},
+		{Name: "example.com", Type: "MX", Content: "mail.example.com", TTL: 3600, Prio: 10},
+		{Name: "_sip._tcp.example.com", Type: "SRV", Content: "5 5060 sip.example.net", TTL: 3600, Prio: 20},
+		{Name: "txt.example.com", Type: "TXT", Content: strings.Repeat("a", 300) + ` "quoted"`, TTL: 3600},
+		{Name: "redirect.example.com", Type: "URL", Content: "https://example.net", TTL: 3600},
+	}
+
+	formatted := Format("example.com", 3600, records)
+	if !strings.HasPrefix(formatted, "$ORIGIN example.com.\n$TTL 3600\nexample.com.\t86400\tIN\tSOA\t") {
+		t.Errorf("expected the SOA record after the directives, got:\n%s", formatted)
+	}
+	if !strings.Contains(formatted, "; redirect.example.com.\t3600\tIN\tURL\thttps://example.net\n") {
+		t.Errorf("expected the URL record as comment, got:\n%s", formatted)
+	}
+
+	parsed, err := Parse(formatted, "example.com", 3600)
+	if err != nil {
+		t.Fatalf("could not parse formatted zone: %v\n%s", err, formatted)
+	}
+
+	want := []Record{
+		{Name: "example.com", Type: "SOA", Content: "ns.inwx.de. hostmaster.example.com. 2024010101 10800 3600 604800 3600", TTL: 86400},
+		{Name: "_sip._tcp.example.com", Type: "SRV", Content: "5 5060 sip.example.net", TTL: 3600, Prio: 20},
+		{Name: "example.com", Type: "MX", Content: "mail.example.com", TTL: 3600, Prio: 10},
+		{Name: "txt.example.com", Type: "TXT", Content: strings.Repeat("a", 300) + ` "quoted"`, TTL: 3600},
+		{Name: "www.example.com", Type: "A", Content: "192.0.2.1", TTL: 300},
+	}
+	if !reflect.DeepEqual(parsed, want) {
+		t.Errorf("unexpected records after round trip\n got: %+v\nwant: %+v", parsed, want)
+	}
+}
+
+func TestFormatRoundTripSpfAndUri(t *testing.T) {
+	records := []Record{
+		{Name: "example.com", Type: "SPF", Content: "v=spf1 include:example.net -all", TTL: 3600},
+		{Name: "_ftp._tcp.example.com", Type: "URI", Content: `1 "ftp://ftp.example.com/public"`, TTL: 3600, Prio: 10},
+	}
+
+	formatted := Format("example.com", 3600, records)
+	if !strings.Contains(formatted, "example.com.\t3600\tIN\tSPF\t\"v=spf1 include:example.net -all\"\n") {
+		t.Errorf("expected the SPF record to be quoted, got:\n%s", formatted)
+	}
+	if !strings.Contains(formatted, "_ftp._tcp.example.com.\t3600\tIN\tURI\t10 1 \"ftp://ftp.example.com/public\"\n") {
+		t.Errorf("expected the URI record with priority, got:\n%s", formatted)
+	}
+
+	parsed, err := Parse(formatted, "example.com", 3600)
+	if err != nil {
+		t.Fatalf("could not parse formatted zone: %v\n%s", err, formatted)
+	}
+
+	want := []Record{
+		{Name: "_ftp._tcp.example.com", Type: "URI", Content: `1 "ftp://ftp.example.com/public"`, TTL: 3600, Prio: 10},
+		{Name: "example.com", Type: "SPF", Content: "v=spf1 include:example.net -all", TTL: 3600},
+	}
+	if !reflect.DeepEqual(parsed, want) {
+		t.Errorf("unexpected records after round trip\n got: %+v\nwant: %+v", parsed, want)
+	}
+}
+
+func TestFormatTxtMultiByte(t *testing.T) {
+	// the 255th byte is the first byte of a two byte character
+	content := strings.Repeat("a", 254) + strings.Repeat("ä", 10)
+
+	formatted := formatTxt(content)
+	for _, segment := range strings.Split(formatted, "\" \"") {
+		if !utf8.ValidString(segment) {
+			t.Errorf("expected character-strings of valid UTF-8, got %q", segment)
+		}
+	}
+
+	parsed, err := Parse("txt TXT "+formatted+"\n", "example.com", 3600)
+	if err != nil {
+		t.Fatalf("could not parse formatted TXT record: %v", err)
+	}
+	if len(parsed) != 1 || parsed[0].Content != content {
+		t.Errorf("expected the content after round trip, got %+v", parsed)
+	}
+}
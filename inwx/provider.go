@@ -4,12 +4,17 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/inwx/terraform-provider-inwx/inwx/internal/api"
+	"github.com/inwx/terraform-provider-inwx/inwx/internal/datasource"
 	"github.com/inwx/terraform-provider-inwx/inwx/internal/resource"
+	"github.com/pquerna/otp/totp"
 )
 
 func Provider() *schema.Provider {
@@ -44,15 +49,89 @@ func Provider() *schema.Provider {
 				Sensitive:   true,
 				DefaultFunc: schema.EnvDefaultFunc("INWX_TAN", nil),
 			},
+			"tan_secret": {
+				Type: schema.TypeString,
+				Description: "Shared secret of the two factor authentication. When set, the TAN is generated from it and " +
+					"`tan` is ignored. Can be passed as `INWX_TAN_SECRET` env var.",
+				Optional:    true,
+				Sensitive:   true,
+				DefaultFunc: schema.EnvDefaultFunc("INWX_TAN_SECRET", nil),
+			},
+			"request_timeout": {
+				Type: schema.TypeInt,
+				Description: "Timeout of a single api request in seconds. Requests are not retried. " +
+					"Can be passed as `INWX_REQUEST_TIMEOUT` env var.",
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("INWX_REQUEST_TIMEOUT", 60),
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"proxy_url": {
+				Type: schema.TypeString,
+				Description: "URL of a HTTP or SOCKS5 proxy for the api requests, e.g. `http://proxy.example.com:3128` or " +
+					"`socks5://proxy.example.com:1080`. Defaults to the `HTTP_PROXY`, `HTTPS_PROXY` and `NO_PROXY` env vars.",
+				Optional: true,
+			},
+			"customer": {
+				Type: schema.TypeString,
+				Description: "Id of a sub customer of a reseller account. All resources are managed for this customer, " +
+					"while the login, the account balance, prices and messages refer to the reseller. Can be passed as " +
+					"`INWX_CUSTOMER` env var.",
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("INWX_CUSTOMER", nil),
+			},
+			"session_cache_path": {
+				Type: schema.TypeString,
+				Description: "File to store the api session in. When set, a valid session of a previous run is reused " +
+					"instead of logging in again and the session is kept at the end of the run. " +
+					"Can be passed as `INWX_SESSION_CACHE_PATH` env var.",
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("INWX_SESSION_CACHE_PATH", nil),
+			},
+			"testing": {
+				Type: schema.TypeBool,
+				Description: "Execute all creates, updates and deletes in testing mode, in which the api only validates " +
+					"them without making changes. Can be passed as `INWX_TESTING` env var.",
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("INWX_TESTING", false),
+			},
+			"default_ttl": {
+				Type:         schema.TypeInt,
+				Description:  "TTL (time to live) of nameserver records that do not set `ttl` themselves.",
+				Optional:     true,
+				Default:      3600,
+				ValidateFunc: validation.IntAtLeast(300),
+			},
+			"compression": {
+				Type:        schema.TypeBool,
+				Description: "Request gzip compressed api responses, which speeds up reading large zones.",
+				Optional:    true,
+				Default:     true,
+			},
 		},
 		ResourcesMap: map[string]*schema.Resource{
 			"inwx_domain":            resource.DomainResource(),
 			"inwx_domain_contact":    resource.DomainContactResource(),
+			"inwx_domain_renewal":    resource.DomainRenewalResource(),
 			"inwx_dnssec_key":        resource.DNSSECKeyResource(),
 			"inwx_nameserver_record": resource.NameserverRecordResource(),
 			"inwx_automated_dnssec":  resource.AutomatedDNSSECResource(),
 			"inwx_nameserver":        resource.NameserverResource(),
 			"inwx_glue_record":       resource.GlueRecordResource(),
+			"inwx_nameserver_set":    resource.NameserverSetResource(),
+			"inwx_url_redirect":      resource.UrlRedirectResource(),
+			"inwx_zone":              resource.ZoneResource(),
+			"inwx_zone_import":       resource.ZoneImportResource(),
+		},
+		DataSourcesMap: map[string]*schema.Resource{
+			"inwx_account":            datasource.AccountDataSource(),
+			"inwx_dnssec_keys":        datasource.DNSSECKeysDataSource(),
+			"inwx_domain_contacts":    datasource.DomainContactsDataSource(),
+			"inwx_domains":            datasource.DomainsDataSource(),
+			"inwx_glue_record":        datasource.GlueRecordDataSource(),
+			"inwx_messages":           datasource.MessagesDataSource(),
+			"inwx_nameserver_records": datasource.NameserverRecordsDataSource(),
+			"inwx_tld":                datasource.TldDataSource(),
+			"inwx_zone_export":        datasource.ZoneExportDataSource(),
 		},
 		ConfigureContextFunc: configureContext,
 	}
@@ -68,48 +147,104 @@ func configureContext(ctx context.Context, data *schema.ResourceData) (interface
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
 			Summary:  "Could not configure context",
-			Detail:   fmt.Sprintf("Could not parse api_url: %w", err),
+			Detail:   fmt.Sprintf("Could not parse api_url: %v", err),
 		})
 		return nil, diags
 	}
 	logger := logr.Discard()
 
-	client, err := api.NewClient(username, password, apiUrl, &logger, false)
-	if err != nil {
-		diags = append(diags, diag.Diagnostic{
-			Severity: diag.Error,
-			Summary:  "Could not configure context",
-			Detail:   fmt.Sprintf("Could not create http client: %w", err),
-		})
-		return nil, diags
-	}
+	timeout := time.Duration(data.Get("request_timeout").(int)) * time.Second
+	sessionCachePath := data.Get("session_cache_path").(string)
 
-	loginParams := map[string]interface{}{
-		"user": username,
-		"pass": password,
+	var proxyUrl *url.URL
+	if rawProxyUrl, ok := data.GetOk("proxy_url"); ok {
+		proxyUrl, err = url.Parse(rawProxyUrl.(string))
+		if err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Could not configure context",
+				Detail:   fmt.Sprintf("Could not parse proxy_url: %v", err),
+			})
+			return nil, diags
+		}
 	}
-	call, err := client.Call(ctx, "account.login", loginParams)
+
+	client, err := api.NewClient(api.ClientOptions{
+		Username:         username,
+		Password:         password,
+		BaseURL:          apiUrl,
+		Logger:           &logger,
+		Timeout:          timeout,
+		ProxyURL:         proxyUrl,
+		Compression:      data.Get("compression").(bool),
+		SessionCachePath: sessionCachePath,
+	})
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
 			Summary:  "Could not configure context",
-			Detail:   fmt.Sprintf("Could not authenticate at api via account.login: %w", err),
+			Detail:   fmt.Sprintf("Could not create http client: %v", err),
 		})
 		return nil, diags
 	}
-	if call.Code() != api.COMMAND_SUCCESSFUL {
-		diags = append(diags, diag.Diagnostic{
-			Severity: diag.Error,
-			Summary:  "Could not configure context",
-			Detail: fmt.Sprintf("Could not authenticate at api via account.login. "+
-				"Got response: %s", call.ApiError()),
-		})
-		return nil, diags
+	client.DefaultTTL = data.Get("default_ttl").(int)
+	client.Customer = data.Get("customer").(string)
+	client.Testing = data.Get("testing").(bool)
+
+	// a session of a previous run is reused as long as it is logged in, its account.info response is kept
+	var call api.Response
+	if sessionCachePath != "" {
+		call = cachedSessionInfo(ctx, client, username)
+	}
+	if call == nil {
+		loginParams := map[string]interface{}{
+			"user": username,
+			"pass": password,
+		}
+		loginCall, err := client.Call(ctx, "account.login", loginParams)
+		if err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Could not configure context",
+				Detail:   fmt.Sprintf("Could not authenticate at api via account.login: %v", err),
+			})
+			return nil, diags
+		}
+		if loginCall.Code() != api.COMMAND_SUCCESSFUL {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Could not configure context",
+				Detail: fmt.Sprintf("Could not authenticate at api via account.login. "+
+					"Got response: %s", loginCall.ErrorMessage()),
+			})
+			return nil, diags
+		}
+
+		call, err = client.Call(ctx, "account.info", map[string]interface{}{})
+		if err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Could not configure context",
+				Detail:   fmt.Sprintf("Could not get account info via account.info: %v", err),
+			})
+			return nil, diags
+		}
 	}
 
-	call, err = client.Call(ctx, "account.info", map[string]interface{}{})
+	tan := data.Get("tan").(string)
+	if tanSecret, ok := data.GetOk("tan_secret"); ok && tanSecret != "" {
+		tan, err = totp.GenerateCode(tanSecret.(string), time.Now())
+		if err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Could not unlock account",
+				Detail:   fmt.Sprintf("Could not generate tan from tan_secret: %v", err),
+			})
+			return nil, diags
+		}
+	}
 
-	if tan, ok := data.GetOk("tan"); ok && call.Code() == 2200 && tan != "" {
+	if call.Code() == 2200 && tan != "" {
 		call, err := client.Call(ctx, "account.unlock", map[string]interface{}{
 			"tan": tan,
 		})
@@ -117,7 +252,7 @@ func configureContext(ctx context.Context, data *schema.ResourceData) (interface
 			diags = append(diags, diag.Diagnostic{
 				Severity: diag.Error,
 				Summary:  "Could not unlock account",
-				Detail:   fmt.Sprintf("Could not authenticate at api via account.unlock: %w", err),
+				Detail:   fmt.Sprintf("Could not authenticate at api via account.unlock: %v", err),
 			})
 			return nil, diags
 		}
@@ -126,11 +261,29 @@ func configureContext(ctx context.Context, data *schema.ResourceData) (interface
 				Severity: diag.Error,
 				Summary:  "Could not unlock account",
 				Detail: fmt.Sprintf("Could not authenticate at api via account.unlock. "+
-					"Got response: %s", call.ApiError()),
+					"Got response: %s", call.ErrorMessage()),
 			})
 			return nil, diags
 		}
 	}
 
+	// the session is not logged out, terraform ends the plugin without notice when it is done. The session expires
+	// on its own, or is reused by the next run with a session cache.
 	return client, diags
 }
+
+// cachedSessionInfo returns the account.info response of the session loaded from the session cache, or nil if the
+// session is not logged in as username anymore.
+func cachedSessionInfo(ctx context.Context, client *api.Client, username string) api.Response {
+	call, err := client.Call(ctx, "account.info", map[string]interface{}{})
+	if err != nil || call.Code() != api.COMMAND_SUCCESSFUL {
+		return nil
+	}
+
+	resData, _ := call["resData"].(map[string]interface{})
+	if sessionUsername, ok := resData["username"].(string); ok && !strings.EqualFold(sessionUsername, username) {
+		return nil
+	}
+
+	return call
+}
@@ -0,0 +1,79 @@
+package inwx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestProvider(t *testing.T) {
+	if err := Provider().InternalValidate(); err != nil {
+		t.Fatalf("invalid provider schema: %v", err)
+	}
+}
+
+func TestConfigureContextSessionCache(t *testing.T) {
+	tests := []struct {
+		name        string
+		loggedIn    bool
+		wantMethods []string
+	}{
+		{name: "valid session", loggedIn: true, wantMethods: []string{"account.info"}},
+		{name: "expired session", loggedIn: false, wantMethods: []string{"account.info", "account.login", "account.info"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var methods []string
+			loggedIn := test.loggedIn
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var body struct {
+					Method string `json:"method"`
+				}
+				json.NewDecoder(r.Body).Decode(&body)
+
+				mu.Lock()
+				defer mu.Unlock()
+				methods = append(methods, body.Method)
+
+				w.Header().Set("content-type", "application/json")
+				switch {
+				case body.Method == "account.login":
+					loggedIn = true
+					fmt.Fprint(w, `{"code":1000}`)
+				case body.Method == "account.info" && loggedIn:
+					fmt.Fprint(w, `{"code":1000,"resData":{"username":"user"}}`)
+				default:
+					fmt.Fprint(w, `{"code":2500,"msg":"Object status prohibits operation"}`)
+				}
+			}))
+			t.Cleanup(server.Close)
+
+			data := schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+				"api_url":            server.URL,
+				"username":           "user",
+				"password":           "password",
+				"session_cache_path": filepath.Join(t.TempDir(), "session"),
+			})
+			_, diags := configureContext(context.Background(), data)
+			if diags.HasError() {
+				t.Fatalf("unexpected error: %v", diags)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if !reflect.DeepEqual(methods, test.wantMethods) {
+				t.Errorf("expected calls %v, got %v", test.wantMethods, methods)
+			}
+		})
+	}
+}